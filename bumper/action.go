@@ -1,6 +1,9 @@
 package bumper
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/bcyran/bumper/pack"
 )
 
@@ -10,6 +13,8 @@ const (
 	ActionSuccessStatus ActionStatus = iota
 	ActionSkippedStatus
 	ActionFailedStatus
+	// ActionPendingStatus is returned by a BatchingAction for the packages it completes later, in Finish
+	ActionPendingStatus
 )
 
 type ActionResult interface {
@@ -22,6 +27,35 @@ type Action interface {
 	Execute(pack *pack.Package) ActionResult
 }
 
+// PreparingAction is an Action which needs to see all the packages before executing for any of them,
+// e.g. to fetch some data in batch.
+type PreparingAction interface {
+	Action
+	Prepare(pkgs []pack.Package)
+}
+
+// RollbackAction is an Action which can revert its changes to the package files.
+// Changes are rolled back when any of the package actions fails, unless they are already committed.
+type RollbackAction interface {
+	Action
+	Rollback(pkg *pack.Package) error
+}
+
+// BatchingAction is an Action which does some of its work once for many packages, after all the packages
+// are processed, e.g. pushes a repository shared by the packages. Execute returns result with ActionPendingStatus
+// for such packages, and no further actions are executed for them.
+type BatchingAction interface {
+	Action
+	// Finish does the pending work and returns the final results, keyed by the package paths.
+	Finish() map[string]ActionResult
+}
+
+// committingAction is an Action which persists the package changes, so they can't be rolled back after it succeeds.
+type committingAction interface {
+	Action
+	commitsChanges()
+}
+
 type BaseActionResult struct {
 	Status ActionStatus
 	Error  error
@@ -34,3 +68,13 @@ func (result *BaseActionResult) GetStatus() ActionStatus {
 func (result *BaseActionResult) GetError() error {
 	return result.Error
 }
+
+// rollbackFailedResult is a result of the failed action which additionally holds the rollback error.
+type rollbackFailedResult struct {
+	ActionResult
+	rollbackErr error
+}
+
+func (result *rollbackFailedResult) GetError() error {
+	return errors.Join(result.ActionResult.GetError(), fmt.Errorf("rollback failed: %w", result.rollbackErr))
+}
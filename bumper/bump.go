@@ -8,13 +8,14 @@ import (
 	"strings"
 
 	"github.com/bcyran/bumper/pack"
+	"go.uber.org/config"
 )
 
-const newPkgrel = "pkgrel=1"
+const newPkgrel = "1"
 
 var (
-	pkgrelPattern = regexp.MustCompile(`pkgrel=\d`)
-	ErrBumpAction = errors.New("bump action error")
+	pkgverLinePattern = regexp.MustCompile(`(?m)^pkgver=`)
+	ErrBumpAction     = errors.New("bump action error")
 )
 
 type bumpActionResult struct {
@@ -22,6 +23,7 @@ type bumpActionResult struct {
 	bumpOk       bool
 	updpkgsumsOk bool
 	makepkgOk    bool
+	pkgverOk     bool
 }
 
 func (result *bumpActionResult) String() string {
@@ -37,15 +39,24 @@ func (result *bumpActionResult) String() string {
 	if !result.makepkgOk {
 		return "makepkg failed"
 	}
+	if !result.pkgverOk {
+		return "pkgver not changed"
+	}
 	return "bumped"
 }
 
 type BumpAction struct {
 	commandRunner CommandRunner
+	bumpConfig    config.Value
+	// keepFailed disables the rollback, so the files can be inspected
+	keepFailed bool
+	snapshots  packageSnapshots
 }
 
-func NewBumpAction(commandRunner CommandRunner) *BumpAction {
-	return &BumpAction{commandRunner: commandRunner}
+func NewBumpAction(commandRunner CommandRunner, bumpConfig config.Value) *BumpAction {
+	action := BumpAction{commandRunner: commandRunner, bumpConfig: bumpConfig}
+	bumpConfig.Get("keepFailed").Populate(&action.keepFailed) //nolint:errcheck
+	return &action
 }
 
 func (action *BumpAction) Execute(pkg *pack.Package) ActionResult {
@@ -56,6 +67,13 @@ func (action *BumpAction) Execute(pkg *pack.Package) ActionResult {
 		return actionResult
 	}
 
+	if err := action.snapshots.take(pkg); err != nil {
+		actionResult.Status = ActionFailedStatus
+		actionResult.Error = fmt.Errorf("%w: %w", ErrBumpAction, err)
+		actionResult.bumpOk = false
+		return actionResult
+	}
+
 	if err := action.bump(pkg); err != nil {
 		actionResult.Status = ActionFailedStatus
 		actionResult.Error = fmt.Errorf("%w: %w", ErrBumpAction, err)
@@ -80,11 +98,24 @@ func (action *BumpAction) Execute(pkg *pack.Package) ActionResult {
 	}
 	actionResult.makepkgOk = true
 
+	if err := action.verifyPkgver(pkg); err != nil {
+		actionResult.Status = ActionFailedStatus
+		actionResult.Error = fmt.Errorf("%w: %w", ErrBumpAction, err)
+		actionResult.pkgverOk = false
+		return actionResult
+	}
+	actionResult.pkgverOk = true
+
 	actionResult.Status = ActionSuccessStatus
 
 	return actionResult
 }
 
+// Rollback restores PKGBUILD and .SRCINFO from before the bump, unless keepFailed is set.
+func (action *BumpAction) Rollback(pkg *pack.Package) error {
+	return action.snapshots.restore(pkg, action.keepFailed)
+}
+
 func (action *BumpAction) bump(pkg *pack.Package) error {
 	pkgbuild, err := os.ReadFile(pkg.PkgbuildPath())
 	if err != nil {
@@ -93,29 +124,84 @@ func (action *BumpAction) bump(pkg *pack.Package) error {
 	updatedPkgbuild := strings.ReplaceAll(
 		string(pkgbuild), pkg.Pkgver.GetVersionStr(), pkg.UpstreamVersion.GetVersionStr(),
 	)
-	if pkg.Pkgrel != "1" {
-		updatedPkgbuild = pkgrelPattern.ReplaceAllString(updatedPkgbuild, newPkgrel)
+	if pkg.Pkgrel != newPkgrel {
+		if updatedPkgbuild, err = setVariable(updatedPkgbuild, "pkgrel", newPkgrel); err != nil {
+			return err
+		}
 	}
-	err = os.WriteFile(pkg.PkgbuildPath(), []byte(updatedPkgbuild), 0o644)
+	if pkg.UpstreamEpoch != "" {
+		if updatedPkgbuild, err = setEpoch(updatedPkgbuild, pkg.UpstreamEpoch); err != nil {
+			return err
+		}
+	}
+	if updatedPkgbuild, err = action.updateVariables(pkg, updatedPkgbuild); err != nil {
+		return err
+	}
+	err = writeFileAtomic(pkg.PkgbuildPath(), []byte(updatedPkgbuild), 0o644)
 	if err != nil {
 		return fmt.Errorf("PKGBUILD writing error: %w", err)
 	}
 	return nil
 }
 
+// setEpoch sets epoch in the PKGBUILD contents, adding it before pkgver if it's not there yet.
+func setEpoch(pkgbuild string, epoch string) (string, error) {
+	if updatedPkgbuild, err := setVariable(pkgbuild, "epoch", epoch); err == nil {
+		return updatedPkgbuild, nil
+	}
+	pkgverLine := pkgverLinePattern.FindStringIndex(pkgbuild)
+	if pkgverLine == nil {
+		return "", errors.New("pkgver not found in PKGBUILD")
+	}
+	return pkgbuild[:pkgverLine[0]] + "epoch=" + epoch + "\n" + pkgbuild[pkgverLine[0]:], nil
+}
+
+// updateVariables sets the extra PKGBUILD variables configured for the package, e.g. commit hash of the new version.
+func (action *BumpAction) updateVariables(pkg *pack.Package, pkgbuild string) (string, error) {
+	templates := map[string]string{}
+	action.bumpConfig.Get("packages").Get(pkg.Pkgbase).Get("variables").Populate(&templates) //nolint:errcheck
+	if len(templates) == 0 {
+		return pkgbuild, nil
+	}
+
+	variables, err := renderVariables(templates, pkg)
+	if err != nil {
+		return "", err
+	}
+	return setVariables(pkgbuild, variables)
+}
+
 func (action *BumpAction) updpkgsums(pkg *pack.Package) error {
 	_, err := action.commandRunner(pkg.Path, "updpkgsums")
 	return err
 }
 
 func (action *BumpAction) makepkg(pkg *pack.Package) error {
-	srcinfo, err := action.commandRunner(pkg.Path, "makepkg", "--printsrcinfo")
+	return writeSrcinfo(action.commandRunner, pkg)
+}
+
+// writeSrcinfo regenerates .SRCINFO of the package.
+func writeSrcinfo(commandRunner CommandRunner, pkg *pack.Package) error {
+	srcinfo, err := commandRunner(pkg.Path, "makepkg", "--printsrcinfo")
 	if err != nil {
 		return err
 	}
-	err = os.WriteFile(pkg.SrcinfoPath(), srcinfo, 0o644)
+	err = writeFileAtomic(pkg.SrcinfoPath(), srcinfo, 0o644)
 	if err != nil {
 		return fmt.Errorf(".SRCINFO writing error: %w", err)
 	}
 	return nil
 }
+
+// verifyPkgver checks whether pkgver in the regenerated .SRCINFO differs from the old one.
+// It doesn't if the PKGBUILD builds pkgver from other variables and none of them has been updated.
+func (action *BumpAction) verifyPkgver(pkg *pack.Package) error {
+	srcinfo, err := pack.ParseSrcinfo(pkg.SrcinfoPath())
+	if err != nil {
+		return err
+	}
+	if srcinfo.Pkgver == pkg.Pkgver {
+		return fmt.Errorf("pkgver in .SRCINFO is still %s, configure bump variables if PKGBUILD doesn't contain it literally", pkg.Pkgver)
+	}
+	return nil
+}
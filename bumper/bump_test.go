@@ -2,6 +2,7 @@ package bumper
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"strings"
 	"testing"
@@ -11,8 +12,11 @@ import (
 	"github.com/bcyran/bumper/upstream"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/config"
 )
 
+var emptyBumpConfig = fakeVersionCheckConfigProvider.Get("empty")
+
 func makeOutdatedPackage(dir string, pkgver string, pkgrel string, upstreamVersion string) *pack.Package {
 	return &pack.Package{
 		Path: dir,
@@ -38,12 +42,22 @@ url='https://foo.bar/{version}/baz'
 	return strings.ReplaceAll(pkgbuild, "{pkgrel}", pkgrel)
 }
 
+func srcinfoString(pkgver string) string {
+	return fmt.Sprintf(`
+pkgbase = foo
+	pkgname = foo
+	url = https://foo.bar
+	pkgver = %s
+	pkgrel = 1
+`, pkgver)
+}
+
 func TestBumpAction_Success(t *testing.T) {
 	versionBefore := "1.0.0"
 	pkgrelBefore := "2"
 	expectedVersion := "2.0.0"
 	expectedPkgrel := "1"
-	expectedSrcinfo := "expected_srcinfo"
+	expectedSrcinfo := srcinfoString(expectedVersion)
 	expectedPkgbuild := pkgbuildString(expectedVersion, expectedPkgrel)
 
 	// build our Package struct and write PKGBUILD
@@ -59,7 +73,7 @@ func TestBumpAction_Success(t *testing.T) {
 	fakeCommandRunner, commandRuns := testutils.MakeFakeCommandRunner(&commandRetvals)
 
 	// execute the action with our mocked command runner
-	action := NewBumpAction(fakeCommandRunner)
+	action := NewBumpAction(fakeCommandRunner, emptyBumpConfig)
 	result := action.Execute(pkg)
 
 	// result assertions
@@ -90,7 +104,7 @@ func TestBumpAction_Skip(t *testing.T) {
 	pkg := &pack.Package{IsOutdated: false}
 
 	fakeCommandRunner, commandRuns := testutils.MakeFakeCommandRunner(&[]testutils.CommandRunnerRetval{})
-	action := NewBumpAction(fakeCommandRunner)
+	action := NewBumpAction(fakeCommandRunner, emptyBumpConfig)
 	result := action.Execute(pkg)
 
 	// result assertions
@@ -105,7 +119,7 @@ func TestBumpAction_FailBump(t *testing.T) {
 	pkg := makeOutdatedPackage(t.TempDir(), "", "", "")
 
 	fakeCommandRunner, _ := testutils.MakeFakeCommandRunner(&[]testutils.CommandRunnerRetval{})
-	action := NewBumpAction(fakeCommandRunner)
+	action := NewBumpAction(fakeCommandRunner, emptyBumpConfig)
 	result := action.Execute(pkg)
 
 	assert.Equal(t, ActionFailedStatus, result.GetStatus())
@@ -125,7 +139,7 @@ func TestBumpAction_FailUpdpkgsums(t *testing.T) {
 	}
 	fakeCommandRunner, _ := testutils.MakeFakeCommandRunner(&commandRetvals)
 
-	action := NewBumpAction(fakeCommandRunner)
+	action := NewBumpAction(fakeCommandRunner, emptyBumpConfig)
 	result := action.Execute(pkg)
 
 	assert.Equal(t, ActionFailedStatus, result.GetStatus())
@@ -146,7 +160,7 @@ func TestBumpAction_FailMakepkg(t *testing.T) {
 	}
 	fakeCommandRunner, _ := testutils.MakeFakeCommandRunner(&commandRetvals)
 
-	action := NewBumpAction(fakeCommandRunner)
+	action := NewBumpAction(fakeCommandRunner, emptyBumpConfig)
 	result := action.Execute(pkg)
 
 	assert.Equal(t, ActionFailedStatus, result.GetStatus())
@@ -154,3 +168,225 @@ func TestBumpAction_FailMakepkg(t *testing.T) {
 	assert.ErrorContains(t, result.GetError(), expectedErr)
 	assert.ErrorContains(t, result.GetError(), "bump action error")
 }
+
+func TestBumpAction_Variables(t *testing.T) {
+	pkgbuildBefore := `
+pkgname=foo
+pkgver=1.0.0
+pkgrel=1
+_commit='0123456789abcdef0123456789abcdef01234567' # v1.0.0
+_tag="v1.0.0"
+_srcname=foo-1.0.0
+source=("git+https://foo.bar/foo#commit=$_commit")
+`
+	expectedPkgbuild := `
+pkgname=foo
+pkgver=2.0.0
+pkgrel=1
+_commit='89abcdef0123456789abcdef0123456789abcdef' # v2.0.0
+_tag="v2.0.0"
+_srcname=foo-2.0.0
+source=("git+https://foo.bar/foo#commit=$_commit")
+`
+	pkg := makeOutdatedPackage(t.TempDir(), "1.0.0", "1", "2.0.0")
+	pkg.Pkgbase = "foo"
+	pkg.UpstreamRevision = upstream.Revision{Tag: "v2.0.0", Commit: "89abcdef0123456789abcdef0123456789abcdef"}
+	require.NoError(t, os.WriteFile(pkg.PkgbuildPath(), []byte(pkgbuildBefore), 0o644))
+	commandRetvals := []testutils.CommandRunnerRetval{
+		{Stdout: []byte{}, Err: nil},                       // retval for updpkgsums
+		{Stdout: []byte(srcinfoString("2.0.0")), Err: nil}, // retval for makepkg --printsrcinfo
+	}
+	fakeCommandRunner, _ := testutils.MakeFakeCommandRunner(&commandRetvals)
+	bumpConfig, _ := config.NewYAML(config.Source(strings.NewReader(
+		`{bump: {packages: {foo: {variables: {_commit: "{{.Commit}}", _tag: "{{.Tag}}", _srcname: "foo-{{.Version}}"}}}}}`,
+	)))
+
+	action := NewBumpAction(fakeCommandRunner, bumpConfig.Get("bump"))
+	result := action.Execute(pkg)
+
+	assert.Equal(t, ActionSuccessStatus, result.GetStatus())
+	pkgbuild, _ := os.ReadFile(pkg.PkgbuildPath())
+	assert.Equal(t, expectedPkgbuild, string(pkgbuild))
+}
+
+func TestBumpAction_VariablesFail(t *testing.T) {
+	cases := []string{
+		// commit is unknown
+		`{bump: {packages: {foo: {variables: {_commit: "{{.Commit}}"}}}}}`,
+		// variable is not present in PKGBUILD
+		`{bump: {packages: {foo: {variables: {_tag: "{{.Version}}"}}}}}`,
+		// invalid template
+		`{bump: {packages: {foo: {variables: {_commit: "{{.Whatever}}"}}}}}`,
+	}
+
+	for _, configStr := range cases {
+		pkg := makeOutdatedPackage(t.TempDir(), "1.0.0", "1", "2.0.0")
+		pkg.Pkgbase = "foo"
+		pkgbuildBefore := pkgbuildString("1.0.0", "1") + "_commit=0123456\n"
+		require.NoError(t, os.WriteFile(pkg.PkgbuildPath(), []byte(pkgbuildBefore), 0o644))
+		fakeCommandRunner, commandRuns := testutils.MakeFakeCommandRunner(&[]testutils.CommandRunnerRetval{})
+		bumpConfig, _ := config.NewYAML(config.Source(strings.NewReader(configStr)))
+
+		action := NewBumpAction(fakeCommandRunner, bumpConfig.Get("bump"))
+		result := action.Execute(pkg)
+
+		assert.Equal(t, ActionFailedStatus, result.GetStatus(), configStr)
+		assert.ErrorIs(t, result.GetError(), ErrBumpAction, configStr)
+		assert.Equal(t, "bump failed", result.String(), configStr)
+		assert.Len(t, *commandRuns, 0, configStr)
+		pkgbuild, _ := os.ReadFile(pkg.PkgbuildPath())
+		assert.Equal(t, pkgbuildBefore, string(pkgbuild), configStr)
+	}
+}
+
+func TestBumpAction_VariableComponents(t *testing.T) {
+	pkgbuildBefore := `
+pkgname=foo
+_major=1
+_minor=2
+_pkgver=1.2.3
+_tag=1.2.3
+pkgver=${_major}.${_minor}
+pkgrel=1
+`
+	expectedPkgbuild := `
+pkgname=foo
+_major=2
+_minor=0
+_pkgver=2.0.1-rc1
+_tag=2.0.1
+pkgver=${_major}.${_minor}
+pkgrel=1
+`
+	pkg := makeOutdatedPackage(t.TempDir(), "1.2", "1", "2.0.1rc1")
+	pkg.Pkgbase = "foo"
+	// raw version is the upstream one, before mangling
+	pkg.UpstreamRawVersion = "2.0.1-rc1"
+	pkg.UpstreamRevision = upstream.Revision{Tag: "v2.0.1"}
+	require.NoError(t, os.WriteFile(pkg.PkgbuildPath(), []byte(pkgbuildBefore), 0o644))
+	commandRetvals := []testutils.CommandRunnerRetval{
+		{Stdout: []byte{}, Err: nil},                     // retval for updpkgsums
+		{Stdout: []byte(srcinfoString("2.0")), Err: nil}, // retval for makepkg --printsrcinfo
+	}
+	fakeCommandRunner, _ := testutils.MakeFakeCommandRunner(&commandRetvals)
+	bumpConfig, _ := config.NewYAML(config.Source(strings.NewReader(
+		`{bump: {packages: {foo: {variables: {_major: "{{.Major}}", _minor: "{{index .Parts 1}}", _pkgver: "{{.Raw}}", _tag: "{{trimPrefix .Tag \"v\"}}"}}}}}`,
+	)))
+
+	action := NewBumpAction(fakeCommandRunner, bumpConfig.Get("bump"))
+	result := action.Execute(pkg)
+
+	assert.Equal(t, ActionSuccessStatus, result.GetStatus())
+	pkgbuild, _ := os.ReadFile(pkg.PkgbuildPath())
+	assert.Equal(t, expectedPkgbuild, string(pkgbuild))
+}
+
+func TestBumpAction_PkgverNotChanged(t *testing.T) {
+	pkgbuildBefore := "pkgname=foo\n_pkgver=1.2.3-rc1\npkgver=${_pkgver//-/}\npkgrel=1\n"
+	pkg := makeOutdatedPackage(t.TempDir(), "1.2.3rc1", "1", "1.2.4")
+	require.NoError(t, os.WriteFile(pkg.PkgbuildPath(), []byte(pkgbuildBefore), 0o644))
+	commandRetvals := []testutils.CommandRunnerRetval{
+		{Stdout: []byte{}, Err: nil},                          // retval for updpkgsums
+		{Stdout: []byte(srcinfoString("1.2.3rc1")), Err: nil}, // retval for makepkg --printsrcinfo
+	}
+	fakeCommandRunner, _ := testutils.MakeFakeCommandRunner(&commandRetvals)
+
+	action := NewBumpAction(fakeCommandRunner, emptyBumpConfig)
+	result := action.Execute(pkg)
+
+	assert.Equal(t, ActionFailedStatus, result.GetStatus())
+	assert.ErrorIs(t, result.GetError(), ErrBumpAction)
+	assert.ErrorContains(t, result.GetError(), "pkgver in .SRCINFO is still 1.2.3rc1")
+	assert.Equal(t, "pkgver not changed", result.String())
+}
+
+func TestBumpAction_Rollback(t *testing.T) {
+	for _, keepFailed := range []bool{false, true} {
+		pkg := makeOutdatedPackage(t.TempDir(), "1.0.0", "1", "2.0.0")
+		pkgbuildBefore := pkgbuildString("1.0.0", "1")
+		srcinfoBefore := srcinfoString("1.0.0")
+		require.NoError(t, os.WriteFile(pkg.PkgbuildPath(), []byte(pkgbuildBefore), 0o644))
+		require.NoError(t, os.WriteFile(pkg.SrcinfoPath(), []byte(srcinfoBefore), 0o644))
+		commandRetvals := []testutils.CommandRunnerRetval{
+			{Stdout: []byte{}, Err: nil},                       // retval for updpkgsums
+			{Stdout: []byte(srcinfoString("2.0.0")), Err: nil}, // retval for makepkg --printsrcinfo
+		}
+		fakeCommandRunner, _ := testutils.MakeFakeCommandRunner(&commandRetvals)
+		bumpConfig, _ := config.NewYAML(config.Source(strings.NewReader(fmt.Sprintf("{bump: {keepFailed: %t}}", keepFailed))))
+
+		action := NewBumpAction(fakeCommandRunner, bumpConfig.Get("bump"))
+		result := action.Execute(pkg)
+		require.Equal(t, ActionSuccessStatus, result.GetStatus())
+		// e.g. the build failed
+		assert.NoError(t, action.Rollback(pkg))
+
+		pkgbuild, _ := os.ReadFile(pkg.PkgbuildPath())
+		srcinfo, _ := os.ReadFile(pkg.SrcinfoPath())
+		if keepFailed {
+			assert.Equal(t, pkgbuildString("2.0.0", "1"), string(pkgbuild))
+			assert.Equal(t, srcinfoString("2.0.0"), string(srcinfo))
+		} else {
+			assert.Equal(t, pkgbuildBefore, string(pkgbuild))
+			assert.Equal(t, srcinfoBefore, string(srcinfo))
+		}
+	}
+}
+
+func TestBumpAction_MultiDigitPkgrel(t *testing.T) {
+	pkg := makeOutdatedPackage(t.TempDir(), "1.0.0", "12", "2.0.0")
+	require.NoError(t, os.WriteFile(pkg.PkgbuildPath(), []byte(pkgbuildString("1.0.0", "12")), 0o644))
+	commandRetvals := []testutils.CommandRunnerRetval{
+		{Stdout: []byte{}, Err: nil},                       // retval for updpkgsums
+		{Stdout: []byte(srcinfoString("2.0.0")), Err: nil}, // retval for makepkg --printsrcinfo
+	}
+	fakeCommandRunner, _ := testutils.MakeFakeCommandRunner(&commandRetvals)
+
+	action := NewBumpAction(fakeCommandRunner, emptyBumpConfig)
+	result := action.Execute(pkg)
+
+	assert.Equal(t, ActionSuccessStatus, result.GetStatus())
+	pkgbuild, _ := os.ReadFile(pkg.PkgbuildPath())
+	assert.Equal(t, pkgbuildString("2.0.0", "1"), string(pkgbuild))
+}
+
+func TestBumpAction_Epoch(t *testing.T) {
+	cases := map[string]struct {
+		epoch            string
+		upstreamEpoch    string
+		pkgbuild         string
+		expectedPkgbuild string
+	}{
+		"added": {
+			upstreamEpoch:    "1",
+			pkgbuild:         pkgbuildString("2023.10", "3"),
+			expectedPkgbuild: strings.Replace(pkgbuildString("1.0", "1"), "pkgver=", "epoch=1\npkgver=", 1),
+		},
+		"updated": {
+			epoch:            "1",
+			upstreamEpoch:    "2",
+			pkgbuild:         "epoch=1\n" + pkgbuildString("2023.10", "3"),
+			expectedPkgbuild: "epoch=2\n" + pkgbuildString("1.0", "1"),
+		},
+	}
+
+	for name, testCase := range cases {
+		t.Run(name, func(t *testing.T) {
+			pkg := makeOutdatedPackage(t.TempDir(), "2023.10", "3", "1.0")
+			pkg.Epoch = testCase.epoch
+			pkg.UpstreamEpoch = testCase.upstreamEpoch
+			require.NoError(t, os.WriteFile(pkg.PkgbuildPath(), []byte(testCase.pkgbuild), 0o644))
+			commandRetvals := []testutils.CommandRunnerRetval{
+				{Stdout: []byte{}, Err: nil},                     // retval for updpkgsums
+				{Stdout: []byte(srcinfoString("1.0")), Err: nil}, // retval for makepkg --printsrcinfo
+			}
+			fakeCommandRunner, _ := testutils.MakeFakeCommandRunner(&commandRetvals)
+
+			action := NewBumpAction(fakeCommandRunner, emptyBumpConfig)
+			result := action.Execute(pkg)
+
+			assert.Equal(t, ActionSuccessStatus, result.GetStatus())
+			pkgbuild, _ := os.ReadFile(pkg.PkgbuildPath())
+			assert.Equal(t, testCase.expectedPkgbuild, string(pkgbuild))
+		})
+	}
+}
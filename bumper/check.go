@@ -19,6 +19,12 @@ type checkActionResult struct {
 	currentVersion  pack.Version
 	upstreamVersion upstream.Version
 	cmpResult       int
+	// currentEpoch is set only if the package has epoch, upstreamEpoch only if it's bumped
+	currentEpoch  string
+	upstreamEpoch string
+	// VCS packages have only the number of commits they are behind, upstream version is known only when refreshed
+	isVCS     bool
+	vcsBehind int
 }
 
 func (result *checkActionResult) String() string {
@@ -28,36 +34,111 @@ func (result *checkActionResult) String() string {
 	if result.Status == ActionSkippedStatus {
 		return result.currentVersion.GetVersionStr()
 	}
+	if result.isVCS {
+		return result.vcsString()
+	}
 	switch result.cmpResult {
 	case 1:
 		return fmt.Sprintf("%s → %s", result.currentVersion, result.upstreamVersion)
 	case 0:
 		return result.currentVersion.GetVersionStr()
 	default:
+		if result.upstreamEpoch != "" {
+			return fmt.Sprintf(
+				"%s → %s (epoch bump)",
+				withEpoch(result.currentEpoch, result.currentVersion.GetVersionStr()),
+				withEpoch(result.upstreamEpoch, result.upstreamVersion.GetVersionStr()),
+			)
+		}
 		return fmt.Sprintf("%s < %s !", result.upstreamVersion, result.currentVersion)
 	}
 }
 
-type versionProviderFactory func(string, config.Value) upstream.VersionProvider
+// withEpoch prefixes the version with the epoch, if it's set.
+func withEpoch(epoch string, version string) string {
+	if epoch == "" {
+		return version
+	}
+	return epoch + ":" + version
+}
+
+func (result *checkActionResult) vcsString() string {
+	if result.vcsBehind == 0 {
+		return result.currentVersion.GetVersionStr()
+	}
+	behind := "behind"
+	if result.vcsBehind != upstream.VCSBehindUnknown {
+		behind = fmt.Sprintf("%d commits behind", result.vcsBehind)
+	}
+	if result.upstreamVersion != "" {
+		return fmt.Sprintf("%s → %s (%s)", result.currentVersion, result.upstreamVersion, behind)
+	}
+	return fmt.Sprintf("%s (%s)", result.currentVersion, behind)
+}
+
+type versionProviderFactory func(url string, pkgbase string, providersConfig config.Value, packageConfig config.Value) (upstream.VersionProvider, string, error)
+
+type pluginProviderFactory func(pluginName string, providersConfig config.Value, pkg upstream.PluginPackage) (upstream.VersionProvider, error)
+
+// namedProvider is a version provider along with its name, as used in the config.
+type namedProvider struct {
+	provider upstream.VersionProvider
+	name     string
+}
 
 type CheckAction struct {
 	versionProviderFactory versionProviderFactory
-	checkConfig            config.Value
+	pluginProviderFactory  pluginProviderFactory
+	// vcsCheck is nil if VCS packages should be skipped
+	vcsCheck    *VCSCheck
+	checkConfig config.Value
+	// preparedProviders holds providers created in Prepare, keyed by package path
+	preparedProviders map[string][]namedProvider
 }
 
-func NewCheckAction(versionProviderFactory versionProviderFactory, checkConfig config.Value) *CheckAction {
-	return &CheckAction{versionProviderFactory: versionProviderFactory, checkConfig: checkConfig}
+func NewCheckAction(versionProviderFactory versionProviderFactory, pluginProviderFactory pluginProviderFactory, vcsCheck *VCSCheck, checkConfig config.Value) *CheckAction {
+	return &CheckAction{
+		versionProviderFactory: versionProviderFactory,
+		pluginProviderFactory:  pluginProviderFactory,
+		vcsCheck:               vcsCheck,
+		checkConfig:            checkConfig,
+	}
+}
+
+// Prepare creates version providers for all the packages and lets them prefetch the versions in batch,
+// where it's supported.
+func (action *CheckAction) Prepare(pkgs []pack.Package) {
+	action.preparedProviders = map[string][]namedProvider{}
+	allProviders := []upstream.VersionProvider{}
+	for i := range pkgs {
+		pkg := &pkgs[i]
+		if action.versionOverride(pkg) != "" || pkg.IsVCS {
+			continue
+		}
+		providers, err := action.createProviders(pkg)
+		if err != nil {
+			// Execute will try again and report the error
+			continue
+		}
+		action.preparedProviders[pkg.Path] = providers
+		for _, provider := range providers {
+			allProviders = append(allProviders, provider.provider)
+		}
+	}
+
+	if err := upstream.PrefetchVersions(allProviders); err != nil {
+		DebugLogger.Printf("Versions prefetching failed: %v", err)
+	}
 }
 
 func (action *CheckAction) Execute(pkg *pack.Package) ActionResult {
 	actionResult := &checkActionResult{}
 
 	var upstreamVersion upstream.Version
+	// upstreamProvider is the provider which found the version, empty if the version is overridden
+	var upstreamProvider namedProvider
 
-	var pkgVersionOverride string
-	action.checkConfig.Get("versionOverrides").Get(pkg.Pkgbase).Populate(&pkgVersionOverride) // nolint:errcheck
-
-	if pkgVersionOverride != "" {
+	if pkgVersionOverride := action.versionOverride(pkg); pkgVersionOverride != "" {
 		var isValid bool
 		upstreamVersion, isValid = upstream.ParseVersion(pkgVersionOverride)
 		if !isValid {
@@ -67,14 +148,20 @@ func (action *CheckAction) Execute(pkg *pack.Package) ActionResult {
 		}
 	} else {
 		if pkg.IsVCS {
-			actionResult.currentVersion = pkg.Pkgver
-			actionResult.Status = ActionSkippedStatus
-			return actionResult
+			return action.executeVCS(pkg, actionResult)
 		}
 
-		upstreamUrls := getPackageUrls(pkg)
+		providers, isPrepared := action.preparedProviders[pkg.Path]
+		if !isPrepared {
+			var err error
+			if providers, err = action.createProviders(pkg); err != nil {
+				actionResult.Status = ActionFailedStatus
+				actionResult.Error = fmt.Errorf("%w: %w", ErrCheckAction, err)
+				return actionResult
+			}
+		}
 		var err error
-		upstreamVersion, err = action.tryGetUpstreamVersion(upstreamUrls)
+		upstreamVersion, upstreamProvider, err = tryGetUpstreamVersion(providers)
 		if err != nil {
 			actionResult.Status = ActionFailedStatus
 			actionResult.Error = err
@@ -82,18 +169,67 @@ func (action *CheckAction) Execute(pkg *pack.Package) ActionResult {
 		}
 	}
 
-	cmpResult := pack.VersionCmp(upstreamVersion, pkg.Pkgver)
+	cmpResult := compareUpstreamVersion(upstreamProvider.provider, upstreamVersion, pkg.Pkgver)
 	pkg.UpstreamVersion = upstreamVersion
 	pkg.IsOutdated = cmpResult == 1
+	if cmpResult == -1 && action.allowEpochBump(pkg) {
+		upstreamEpoch, err := pack.IncrementEpoch(pkg.Epoch)
+		if err != nil {
+			actionResult.Status = ActionFailedStatus
+			actionResult.Error = fmt.Errorf("%w: %w", ErrCheckAction, err)
+			return actionResult
+		}
+		pkg.UpstreamEpoch = upstreamEpoch
+		pkg.IsOutdated = true
+	}
+	if pkg.IsOutdated {
+		pkg.UpstreamRevision = getUpstreamRevision(upstreamProvider.provider)
+		pkg.UpstreamRawVersion = getUpstreamRawVersion(upstreamProvider.provider)
+		pkg.UpstreamProvider = upstreamProvider.name
+		pkg.UpstreamReleaseNotes = getUpstreamReleaseNotes(upstreamProvider.provider)
+	}
 
 	actionResult.Status = ActionSuccessStatus
 	actionResult.currentVersion = pkg.Pkgver
 	actionResult.upstreamVersion = upstreamVersion
 	actionResult.cmpResult = cmpResult
+	actionResult.currentEpoch = pkg.Epoch
+	actionResult.upstreamEpoch = pkg.UpstreamEpoch
 
 	return actionResult
 }
 
+// allowEpochBump checks whether upstream version lower than the current one should bump the package epoch.
+func (action *CheckAction) allowEpochBump(pkg *pack.Package) bool {
+	var allowEpochBump bool
+	action.checkConfig.Get("packages").Get(pkg.Pkgbase).Get("allowEpochBump").Populate(&allowEpochBump) // nolint:errcheck
+	return allowEpochBump
+}
+
+// executeVCS checks the VCS package with vcsCheck, or skips it if VCS check is disabled.
+func (action *CheckAction) executeVCS(pkg *pack.Package, actionResult *checkActionResult) ActionResult {
+	if action.vcsCheck == nil {
+		actionResult.currentVersion = pkg.Pkgver
+		actionResult.Status = ActionSkippedStatus
+		return actionResult
+	}
+
+	if err := action.vcsCheck.execute(pkg, actionResult); err != nil {
+		actionResult.Status = ActionFailedStatus
+		actionResult.Error = fmt.Errorf("%w: %w", ErrCheckAction, err)
+		return actionResult
+	}
+	actionResult.Status = ActionSuccessStatus
+	return actionResult
+}
+
+// versionOverride returns version override configured for the package, or empty string if none.
+func (action *CheckAction) versionOverride(pkg *pack.Package) string {
+	var pkgVersionOverride string
+	action.checkConfig.Get("versionOverrides").Get(pkg.Pkgbase).Populate(&pkgVersionOverride) // nolint:errcheck
+	return pkgVersionOverride
+}
+
 // getPackageUrls extracts all relevant URLs from given package.
 // This includes both 'url' field and 'source' fields.
 func getPackageUrls(pkg *pack.Package) []string {
@@ -111,36 +247,110 @@ func getPackageUrls(pkg *pack.Package) []string {
 	return urls
 }
 
-// tryGetUpstreamVersion tries to create and use a version provider for each of the given URLs.
-func (action *CheckAction) tryGetUpstreamVersion(urls []string) (upstream.Version, error) {
+// createProviders tries to create a version provider for each of the package URLs,
+// or just the upstream URL if it's configured for the package.
+// If a plugin is configured for the package, it's the only provider.
+func (action *CheckAction) createProviders(pkg *pack.Package) ([]namedProvider, error) {
 	providersConfig := action.checkConfig.Get("providers")
-	providers := []upstream.VersionProvider{}
+	packageConfig := action.checkConfig.Get("packages").Get(pkg.Pkgbase)
+
+	urls := getPackageUrls(pkg)
+	var upstreamURL string
+	packageConfig.Get("upstreamUrl").Populate(&upstreamURL) // nolint:errcheck
+	if upstreamURL != "" {
+		urls = []string{upstreamURL}
+	}
+
+	var pluginName string
+	packageConfig.Get("plugin").Populate(&pluginName) // nolint:errcheck
+	if pluginName != "" {
+		pluginPkg := upstream.PluginPackage{Pkgbase: pkg.Pkgbase, URLs: urls, Version: pkg.Pkgver.GetVersionStr()}
+		pluginProvider, err := action.pluginProviderFactory(pluginName, providersConfig, pluginPkg)
+		if err != nil {
+			return nil, err
+		}
+		return []namedProvider{{provider: pluginProvider, name: upstream.PluginProviderName}}, nil
+	}
+
+	providers := []namedProvider{}
 	for _, url := range urls {
-		if newProvider := action.versionProviderFactory(url, providersConfig); newProvider != nil {
-			providers = appendUnique(providers, newProvider)
+		newProvider, providerName, err := action.versionProviderFactory(url, pkg.Pkgbase, providersConfig, packageConfig)
+		if err != nil {
+			return nil, err
+		}
+		if newProvider != nil {
+			providers = appendUnique(providers, namedProvider{provider: newProvider, name: providerName})
 		}
 	}
+	return providers, nil
+}
 
+// tryGetUpstreamVersion tries to get the latest version from each of the given providers, until one succeeds.
+// Returns the version and the provider which found it.
+func tryGetUpstreamVersion(providers []namedProvider) (upstream.Version, namedProvider, error) {
 	if len(providers) == 0 {
-		return upstream.Version(""), fmt.Errorf("no upstream provider found")
+		return upstream.Version(""), namedProvider{}, fmt.Errorf("no upstream provider found")
 	}
 
 	upstreamErrs := []error{}
 	for _, provider := range providers {
-		upstreamVersion, err := provider.LatestVersion()
+		upstreamVersion, err := provider.provider.LatestVersion()
 		if err == nil {
-			return upstreamVersion, nil
+			return upstreamVersion, provider, nil
 		}
 		upstreamErrs = append(upstreamErrs, fmt.Errorf("upstream provider error: %w", err))
 	}
 
-	return upstream.Version(""), errors.Join(upstreamErrs...)
+	return upstream.Version(""), namedProvider{}, errors.Join(upstreamErrs...)
+}
+
+// compareUpstreamVersion compares the upstream version with the current one, the way the provider requires, if it does.
+func compareUpstreamVersion(provider upstream.VersionProvider, upstreamVersion upstream.Version, currentVersion pack.Version) int {
+	comparingProvider, isComparingProvider := provider.(upstream.ComparingProvider)
+	if !isComparingProvider {
+		return pack.VersionCmp(upstreamVersion, currentVersion)
+	}
+	return comparingProvider.CompareVersions(upstreamVersion.GetVersionStr(), currentVersion.GetVersionStr())
+}
+
+// getUpstreamRevision returns tag and commit of the version found by the provider, if the provider supports it.
+// Revision is needed only by some of the bump variables, so errors are just logged.
+func getUpstreamRevision(provider upstream.VersionProvider) upstream.Revision {
+	revisionProvider, isRevisionProvider := provider.(upstream.RevisionProvider)
+	if !isRevisionProvider {
+		return upstream.Revision{}
+	}
+	revision, err := revisionProvider.LatestRevision()
+	if err != nil {
+		DebugLogger.Printf("Upstream revision not found: %v", err)
+		return upstream.Revision{}
+	}
+	return revision
+}
+
+// getUpstreamRawVersion returns upstream version string found by the provider, before it was normalised,
+// if the provider supports it.
+func getUpstreamRawVersion(provider upstream.VersionProvider) string {
+	rawVersionProvider, isRawVersionProvider := provider.(upstream.RawVersionProvider)
+	if !isRawVersionProvider {
+		return ""
+	}
+	return rawVersionProvider.LatestRawVersion()
+}
+
+// getUpstreamReleaseNotes returns notes of the release found by the provider, if the provider supports it.
+func getUpstreamReleaseNotes(provider upstream.VersionProvider) string {
+	releaseNotesProvider, isReleaseNotesProvider := provider.(upstream.ReleaseNotesProvider)
+	if !isReleaseNotesProvider {
+		return ""
+	}
+	return releaseNotesProvider.LatestReleaseNotes()
 }
 
-func appendUnique(providers []upstream.VersionProvider, newProvider upstream.VersionProvider) []upstream.VersionProvider {
+func appendUnique(providers []namedProvider, newProvider namedProvider) []namedProvider {
 	isUnique := true
 	for _, existingProvider := range providers {
-		if newProvider.Equal(existingProvider) {
+		if newProvider.provider.Equal(existingProvider.provider) {
 			isUnique = false
 		}
 	}
@@ -44,10 +44,10 @@ func (provider *fakeVersionProvider) Equal(_ interface{}) bool {
 }
 
 func TestCheckAction_Success(t *testing.T) {
-	verProvFactory := func(_url string, providersConfig config.Value) upstream.VersionProvider {
-		return &fakeVersionProvider{version: providersConfig.Get("fakeVersionProvider").String()}
+	verProvFactory := func(_url string, _pkgbase string, providersConfig config.Value, _packageConfig config.Value) (upstream.VersionProvider, string, error) {
+		return &fakeVersionProvider{version: providersConfig.Get("fakeVersionProvider").String()}, "fake", nil
 	}
-	action := NewCheckAction(verProvFactory, fakeVersionCheckConfig)
+	action := NewCheckAction(verProvFactory, nil, nil, fakeVersionCheckConfig)
 	pkg := pack.Package{
 		Srcinfo: &pack.Srcinfo{
 			URL: "foo",
@@ -64,15 +64,16 @@ func TestCheckAction_Success(t *testing.T) {
 	assert.Equal(t, "1.0.0 → 2.0.0", result.String())
 	// package assertions
 	assert.Equal(t, upstream.Version("2.0.0"), pkg.UpstreamVersion)
+	assert.Equal(t, "fake", pkg.UpstreamProvider)
 	assert.True(t, pkg.IsOutdated)
 }
 
 func TestCheckAction_SuccessVersionOverride(t *testing.T) {
-	verProvFactory := func(_url string, _providersConfig config.Value) upstream.VersionProvider {
+	verProvFactory := func(_url string, _pkgbase string, _providersConfig config.Value, _packageConfig config.Value) (upstream.VersionProvider, string, error) {
 		t.Error("provider should not be called when version override provided")
-		return nil
+		return nil, "", nil
 	}
-	action := NewCheckAction(verProvFactory, versionOverrideCheckConfig)
+	action := NewCheckAction(verProvFactory, nil, nil, versionOverrideCheckConfig)
 	pkg := pack.Package{
 		Srcinfo: &pack.Srcinfo{
 			Pkgbase: "foopkg",
@@ -94,8 +95,10 @@ func TestCheckAction_SuccessVersionOverride(t *testing.T) {
 }
 
 func TestCheckAction_Skip(t *testing.T) {
-	verProvFactory := func(_url string, _providersConfig config.Value) upstream.VersionProvider { return nil }
-	action := NewCheckAction(verProvFactory, emptyCheckConfig)
+	verProvFactory := func(_url string, _pkgbase string, _providersConfig config.Value, _packageConfig config.Value) (upstream.VersionProvider, string, error) {
+		return nil, "", nil
+	}
+	action := NewCheckAction(verProvFactory, nil, nil, emptyCheckConfig)
 	pkg := pack.Package{
 		Srcinfo: &pack.Srcinfo{
 			URL: "foo", FullVersion: &pack.FullVersion{
@@ -112,8 +115,10 @@ func TestCheckAction_Skip(t *testing.T) {
 }
 
 func TestCheckAction_FailNoProvider(t *testing.T) {
-	verProvFactory := func(_url string, _providersConfig config.Value) upstream.VersionProvider { return nil }
-	action := NewCheckAction(verProvFactory, emptyCheckConfig)
+	verProvFactory := func(_url string, _pkgbase string, _providersConfig config.Value, _packageConfig config.Value) (upstream.VersionProvider, string, error) {
+		return nil, "", nil
+	}
+	action := NewCheckAction(verProvFactory, nil, nil, emptyCheckConfig)
 	pkg := pack.Package{Srcinfo: &pack.Srcinfo{URL: "foo"}}
 
 	result := action.Execute(&pkg)
@@ -125,10 +130,10 @@ func TestCheckAction_FailNoProvider(t *testing.T) {
 
 func TestCheckAction_FailProviderFailed(t *testing.T) {
 	const expectedErr = "some random error"
-	verProvFactory := func(_url string, _providersConfig config.Value) upstream.VersionProvider {
-		return &fakeVersionProvider{err: errors.New(expectedErr)}
+	verProvFactory := func(_url string, _pkgbase string, _providersConfig config.Value, _packageConfig config.Value) (upstream.VersionProvider, string, error) {
+		return &fakeVersionProvider{err: errors.New(expectedErr)}, "fake", nil
 	}
-	action := NewCheckAction(verProvFactory, emptyCheckConfig)
+	action := NewCheckAction(verProvFactory, nil, nil, emptyCheckConfig)
 	pkg := pack.Package{Srcinfo: &pack.Srcinfo{URL: "foo"}}
 
 	result := action.Execute(&pkg)
@@ -141,11 +146,11 @@ func TestCheckAction_FailProviderFailed(t *testing.T) {
 func TestCheckAction_FailChecksMultipleURLs(t *testing.T) {
 	const expectedErr = "some random error"
 	checkedURLs := []string{}
-	verProvFactory := func(url string, _providersConfig config.Value) upstream.VersionProvider {
+	verProvFactory := func(url string, _pkgbase string, _providersConfig config.Value, _packageConfig config.Value) (upstream.VersionProvider, string, error) {
 		checkedURLs = append(checkedURLs, url)
-		return &fakeVersionProvider{err: errors.New(expectedErr)}
+		return &fakeVersionProvider{err: errors.New(expectedErr)}, "fake", nil
 	}
-	action := NewCheckAction(verProvFactory, emptyCheckConfig)
+	action := NewCheckAction(verProvFactory, nil, nil, emptyCheckConfig)
 	pkg := pack.Package{
 		Srcinfo: &pack.Srcinfo{
 			URL:    "first.url",
@@ -164,11 +169,11 @@ func TestCheckAction_FailChecksMultipleURLs(t *testing.T) {
 }
 
 func TestCheckAction_FailInvalidVersionOverride(t *testing.T) {
-	verProvFactory := func(_url string, _providersConfig config.Value) upstream.VersionProvider {
+	verProvFactory := func(_url string, _pkgbase string, _providersConfig config.Value, _packageConfig config.Value) (upstream.VersionProvider, string, error) {
 		t.Error("provider should not be called when version override provided")
-		return nil
+		return nil, "", nil
 	}
-	action := NewCheckAction(verProvFactory, invalidVersionOverrideCheckConfig)
+	action := NewCheckAction(verProvFactory, nil, nil, invalidVersionOverrideCheckConfig)
 	pkg := pack.Package{
 		Srcinfo: &pack.Srcinfo{
 			Pkgbase: "foopkg",
@@ -212,3 +217,356 @@ func TestCheckActionResult_String(t *testing.T) {
 		assert.Equal(t, expectedString, result.String())
 	}
 }
+
+func TestCheckAction_Prepare(t *testing.T) {
+	createdProviders := map[string]int{}
+	verProvFactory := func(url string, _pkgbase string, _providersConfig config.Value, _packageConfig config.Value) (upstream.VersionProvider, string, error) {
+		createdProviders[url]++
+		return &fakeVersionProvider{version: "2.0.0"}, "fake", nil
+	}
+	action := NewCheckAction(verProvFactory, nil, nil, versionOverrideCheckConfig)
+	packages := []pack.Package{
+		{
+			Path: "/regular",
+			Srcinfo: &pack.Srcinfo{
+				Pkgbase:     "regular",
+				URL:         "regular.url",
+				FullVersion: &pack.FullVersion{Pkgver: pack.Version("1.0.0")},
+			},
+		},
+		{
+			Path: "/foopkg",
+			Srcinfo: &pack.Srcinfo{
+				Pkgbase:     "foopkg",
+				URL:         "overridden.url",
+				FullVersion: &pack.FullVersion{Pkgver: pack.Version("1.0.0")},
+			},
+		},
+		{
+			Path: "/vcs",
+			Srcinfo: &pack.Srcinfo{
+				Pkgbase:     "vcs",
+				URL:         "vcs.url",
+				FullVersion: &pack.FullVersion{Pkgver: pack.Version("1.0.0")},
+			},
+			IsVCS: true,
+		},
+	}
+
+	action.Prepare(packages)
+	// providers are created only for packages which will need them
+	assert.Equal(t, map[string]int{"regular.url": 1}, createdProviders)
+
+	for i := range packages {
+		result := action.Execute(&packages[i])
+		assert.NotEqual(t, ActionFailedStatus, result.GetStatus())
+	}
+	// prepared providers are reused
+	assert.Equal(t, map[string]int{"regular.url": 1}, createdProviders)
+	assert.Equal(t, upstream.Version("2.0.0"), packages[0].UpstreamVersion)
+}
+
+func TestCheckAction_PassesPackageConfig(t *testing.T) {
+	configProvider, _ := config.NewYAML(config.Source(strings.NewReader("{check: {packages: {foopkg: {tagPattern: foo}}}}")))
+	var receivedPackageConfig config.Value
+	verProvFactory := func(_url string, _pkgbase string, _providersConfig config.Value, packageConfig config.Value) (upstream.VersionProvider, string, error) {
+		receivedPackageConfig = packageConfig
+		return &fakeVersionProvider{version: "2.0.0"}, "fake", nil
+	}
+	action := NewCheckAction(verProvFactory, nil, nil, configProvider.Get("check"))
+	pkg := pack.Package{
+		Srcinfo: &pack.Srcinfo{
+			Pkgbase:     "foopkg",
+			URL:         "foo",
+			FullVersion: &pack.FullVersion{Pkgver: pack.Version("1.0.0")},
+		},
+	}
+
+	result := action.Execute(&pkg)
+
+	assert.Equal(t, ActionSuccessStatus, result.GetStatus())
+	assert.Equal(t, "foo", receivedPackageConfig.Get("tagPattern").String())
+}
+
+func TestCheckAction_FailInvalidPackageConfig(t *testing.T) {
+	const expectedErr = "invalid tag pattern"
+	verProvFactory := func(_url string, _pkgbase string, _providersConfig config.Value, _packageConfig config.Value) (upstream.VersionProvider, string, error) {
+		return nil, "", errors.New(expectedErr)
+	}
+	action := NewCheckAction(verProvFactory, nil, nil, emptyCheckConfig)
+	pkg := pack.Package{Srcinfo: &pack.Srcinfo{URL: "foo"}}
+
+	action.Prepare([]pack.Package{pkg})
+	result := action.Execute(&pkg)
+
+	assert.Equal(t, ActionFailedStatus, result.GetStatus())
+	assert.ErrorIs(t, result.GetError(), ErrCheckAction)
+	assert.ErrorContains(t, result.GetError(), expectedErr)
+}
+
+func TestCheckAction_UpstreamURL(t *testing.T) {
+	configProvider, _ := config.NewYAML(config.Source(strings.NewReader("{check: {packages: {foopkg: {upstreamUrl: upstream.url}}}}")))
+	checkedURLs := []string{}
+	verProvFactory := func(url string, _pkgbase string, _providersConfig config.Value, _packageConfig config.Value) (upstream.VersionProvider, string, error) {
+		checkedURLs = append(checkedURLs, url)
+		return &fakeVersionProvider{version: "2.0.0"}, "fake", nil
+	}
+	action := NewCheckAction(verProvFactory, nil, nil, configProvider.Get("check"))
+	pkg := pack.Package{
+		Srcinfo: &pack.Srcinfo{
+			Pkgbase:     "foopkg",
+			URL:         "first.url",
+			Source:      []string{"second.url"},
+			FullVersion: &pack.FullVersion{Pkgver: pack.Version("1.0.0")},
+		},
+	}
+
+	result := action.Execute(&pkg)
+
+	assert.Equal(t, ActionSuccessStatus, result.GetStatus())
+	// package URLs are not checked at all
+	assert.Equal(t, []string{"upstream.url"}, checkedURLs)
+}
+
+func TestCheckAction_Plugin(t *testing.T) {
+	configProvider, _ := config.NewYAML(config.Source(strings.NewReader("{check: {packages: {foopkg: {plugin: portal}}}}")))
+	verProvFactory := func(_url string, _pkgbase string, _providersConfig config.Value, _packageConfig config.Value) (upstream.VersionProvider, string, error) {
+		return &fakeVersionProvider{version: "3.0.0"}, "fake", nil
+	}
+	var receivedPluginName string
+	var receivedPluginPkg upstream.PluginPackage
+	pluginProvFactory := func(pluginName string, _providersConfig config.Value, pkg upstream.PluginPackage) (upstream.VersionProvider, error) {
+		receivedPluginName = pluginName
+		receivedPluginPkg = pkg
+		return &fakeVersionProvider{version: "2.0.0"}, nil
+	}
+	action := NewCheckAction(verProvFactory, pluginProvFactory, nil, configProvider.Get("check"))
+	pkg := pack.Package{
+		Srcinfo: &pack.Srcinfo{
+			Pkgbase:     "foopkg",
+			URL:         "first.url",
+			Source:      []string{"second.url"},
+			FullVersion: &pack.FullVersion{Pkgver: pack.Version("1.0.0")},
+		},
+	}
+
+	result := action.Execute(&pkg)
+
+	assert.Equal(t, ActionSuccessStatus, result.GetStatus())
+	assert.Equal(t, upstream.Version("2.0.0"), pkg.UpstreamVersion)
+	assert.Equal(t, "plugin", pkg.UpstreamProvider)
+	assert.Equal(t, "portal", receivedPluginName)
+	expectedPluginPkg := upstream.PluginPackage{Pkgbase: "foopkg", URLs: []string{"first.url", "second.url"}, Version: "1.0.0"}
+	assert.Equal(t, expectedPluginPkg, receivedPluginPkg)
+}
+
+func TestCheckAction_FailUnknownPlugin(t *testing.T) {
+	configProvider, _ := config.NewYAML(config.Source(strings.NewReader("{check: {packages: {foopkg: {plugin: missing}}}}")))
+	pluginProvFactory := func(_pluginName string, _providersConfig config.Value, _pkg upstream.PluginPackage) (upstream.VersionProvider, error) {
+		return nil, upstream.ErrInvalidPackageConfig
+	}
+	action := NewCheckAction(nil, pluginProvFactory, nil, configProvider.Get("check"))
+	pkg := pack.Package{
+		Srcinfo: &pack.Srcinfo{
+			Pkgbase:     "foopkg",
+			URL:         "foo",
+			FullVersion: &pack.FullVersion{Pkgver: pack.Version("1.0.0")},
+		},
+	}
+
+	result := action.Execute(&pkg)
+
+	assert.Equal(t, ActionFailedStatus, result.GetStatus())
+	assert.ErrorIs(t, result.GetError(), upstream.ErrInvalidPackageConfig)
+}
+
+type fakeRevisionProvider struct {
+	fakeVersionProvider
+	revision upstream.Revision
+	err      error
+}
+
+func (provider *fakeRevisionProvider) LatestRevision() (upstream.Revision, error) {
+	return provider.revision, provider.err
+}
+
+func TestCheckAction_Revision(t *testing.T) {
+	revision := upstream.Revision{Tag: "v2.0.0", Commit: "0123456"}
+	cases := []struct {
+		provider         *fakeRevisionProvider
+		expectedRevision upstream.Revision
+	}{
+		{&fakeRevisionProvider{fakeVersionProvider: fakeVersionProvider{version: "2.0.0"}, revision: revision}, revision},
+		// revision errors are ignored
+		{&fakeRevisionProvider{fakeVersionProvider: fakeVersionProvider{version: "2.0.0"}, err: errors.New("rate limit")}, upstream.Revision{}},
+		// revision is not needed if the package is up to date
+		{&fakeRevisionProvider{fakeVersionProvider: fakeVersionProvider{version: "1.0.0"}, revision: revision}, upstream.Revision{}},
+	}
+
+	for _, testCase := range cases {
+		verProvFactory := func(_url string, _pkgbase string, _providersConfig config.Value, _packageConfig config.Value) (upstream.VersionProvider, string, error) {
+			return testCase.provider, "fake", nil
+		}
+		action := NewCheckAction(verProvFactory, nil, nil, emptyCheckConfig)
+		pkg := pack.Package{
+			Srcinfo: &pack.Srcinfo{URL: "foo", FullVersion: &pack.FullVersion{Pkgver: pack.Version("1.0.0")}},
+		}
+
+		result := action.Execute(&pkg)
+
+		assert.Equal(t, ActionSuccessStatus, result.GetStatus())
+		assert.Equal(t, testCase.expectedRevision, pkg.UpstreamRevision)
+	}
+}
+
+// fakeComparingProvider compares versions in reverse order
+type fakeComparingProvider struct {
+	fakeVersionProvider
+}
+
+func (provider *fakeComparingProvider) CompareVersions(a string, b string) int {
+	return pack.Rpmvercmp(b, a)
+}
+
+func TestCheckAction_ComparingProvider(t *testing.T) {
+	cases := map[string]bool{
+		"2.0.0": false,
+		"1.0.0": false,
+		"0.5.0": true,
+	}
+
+	for upstreamVersion, expectedOutdated := range cases {
+		verProvFactory := func(_url string, _pkgbase string, _providersConfig config.Value, _packageConfig config.Value) (upstream.VersionProvider, string, error) {
+			return &fakeComparingProvider{fakeVersionProvider: fakeVersionProvider{version: upstreamVersion}}, "fake", nil
+		}
+		action := NewCheckAction(verProvFactory, nil, nil, emptyCheckConfig)
+		pkg := pack.Package{
+			Srcinfo: &pack.Srcinfo{URL: "foo", FullVersion: &pack.FullVersion{Pkgver: pack.Version("1.0.0")}},
+		}
+
+		result := action.Execute(&pkg)
+
+		assert.Equal(t, ActionSuccessStatus, result.GetStatus())
+		assert.Equal(t, expectedOutdated, pkg.IsOutdated, upstreamVersion)
+	}
+}
+
+type fakeReleaseNotesProvider struct {
+	fakeVersionProvider
+	releaseNotes string
+}
+
+func (provider *fakeReleaseNotesProvider) LatestReleaseNotes() string {
+	return provider.releaseNotes
+}
+
+func TestCheckAction_ReleaseNotes(t *testing.T) {
+	verProvFactory := func(_url string, _pkgbase string, _providersConfig config.Value, _packageConfig config.Value) (upstream.VersionProvider, string, error) {
+		return &fakeReleaseNotesProvider{fakeVersionProvider: fakeVersionProvider{version: "2.0.0"}, releaseNotes: "Fixed bugs"}, "fake", nil
+	}
+	action := NewCheckAction(verProvFactory, nil, nil, emptyCheckConfig)
+	pkg := pack.Package{
+		Srcinfo: &pack.Srcinfo{URL: "foo", FullVersion: &pack.FullVersion{Pkgver: pack.Version("1.0.0")}},
+	}
+
+	result := action.Execute(&pkg)
+
+	assert.Equal(t, ActionSuccessStatus, result.GetStatus())
+	assert.Equal(t, "Fixed bugs", pkg.UpstreamReleaseNotes)
+}
+
+type fakeRawVersionProvider struct {
+	fakeVersionProvider
+	rawVersion string
+}
+
+func (provider *fakeRawVersionProvider) LatestRawVersion() string {
+	return provider.rawVersion
+}
+
+func TestCheckAction_RawVersion(t *testing.T) {
+	verProvFactory := func(_url string, _pkgbase string, _providersConfig config.Value, _packageConfig config.Value) (upstream.VersionProvider, string, error) {
+		return &fakeRawVersionProvider{fakeVersionProvider: fakeVersionProvider{version: "2.0.0rc1"}, rawVersion: "2.0.0-rc1"}, "fake", nil
+	}
+	action := NewCheckAction(verProvFactory, nil, nil, emptyCheckConfig)
+	pkg := pack.Package{
+		Srcinfo: &pack.Srcinfo{URL: "foo", FullVersion: &pack.FullVersion{Pkgver: pack.Version("1.0.0")}},
+	}
+
+	result := action.Execute(&pkg)
+
+	assert.Equal(t, ActionSuccessStatus, result.GetStatus())
+	assert.Equal(t, upstream.Version("2.0.0rc1"), pkg.UpstreamVersion)
+	assert.Equal(t, "2.0.0-rc1", pkg.UpstreamRawVersion)
+}
+
+func TestCheckAction_EpochBump(t *testing.T) {
+	configProvider, _ := config.NewYAML(config.Source(strings.NewReader("{check: {packages: {foopkg: {allowEpochBump: true}}}}")))
+	verProvFactory := func(_url string, _pkgbase string, _providersConfig config.Value, _packageConfig config.Value) (upstream.VersionProvider, string, error) {
+		return &fakeVersionProvider{version: "1.0"}, "fake", nil
+	}
+	action := NewCheckAction(verProvFactory, nil, nil, configProvider.Get("check"))
+
+	cases := map[string]string{
+		"":  "2023.10 → 1:1.0 (epoch bump)",
+		"1": "1:2023.10 → 2:1.0 (epoch bump)",
+	}
+	for epoch, expectedString := range cases {
+		pkg := pack.Package{
+			Srcinfo: &pack.Srcinfo{
+				Pkgbase:     "foopkg",
+				URL:         "foo",
+				FullVersion: &pack.FullVersion{Epoch: epoch, Pkgver: pack.Version("2023.10")},
+			},
+		}
+
+		result := action.Execute(&pkg)
+
+		assert.Equal(t, ActionSuccessStatus, result.GetStatus())
+		assert.Equal(t, expectedString, result.String())
+		assert.True(t, pkg.IsOutdated)
+		assert.Equal(t, upstream.Version("1.0"), pkg.UpstreamVersion)
+	}
+}
+
+func TestCheckAction_EpochBumpNotAllowed(t *testing.T) {
+	verProvFactory := func(_url string, _pkgbase string, _providersConfig config.Value, _packageConfig config.Value) (upstream.VersionProvider, string, error) {
+		return &fakeVersionProvider{version: "1.0"}, "fake", nil
+	}
+	action := NewCheckAction(verProvFactory, nil, nil, emptyCheckConfig)
+	pkg := pack.Package{
+		Srcinfo: &pack.Srcinfo{
+			Pkgbase:     "foopkg",
+			URL:         "foo",
+			FullVersion: &pack.FullVersion{Pkgver: pack.Version("2023.10")},
+		},
+	}
+
+	result := action.Execute(&pkg)
+
+	assert.Equal(t, ActionSuccessStatus, result.GetStatus())
+	assert.Equal(t, "1.0 < 2023.10 !", result.String())
+	assert.False(t, pkg.IsOutdated)
+	assert.Empty(t, pkg.UpstreamEpoch)
+}
+
+func TestCheckAction_FailEpochBumpInvalidEpoch(t *testing.T) {
+	configProvider, _ := config.NewYAML(config.Source(strings.NewReader("{check: {packages: {foopkg: {allowEpochBump: true}}}}")))
+	verProvFactory := func(_url string, _pkgbase string, _providersConfig config.Value, _packageConfig config.Value) (upstream.VersionProvider, string, error) {
+		return &fakeVersionProvider{version: "1.0"}, "fake", nil
+	}
+	action := NewCheckAction(verProvFactory, nil, nil, configProvider.Get("check"))
+	pkg := pack.Package{
+		Srcinfo: &pack.Srcinfo{
+			Pkgbase:     "foopkg",
+			URL:         "foo",
+			FullVersion: &pack.FullVersion{Epoch: "x", Pkgver: pack.Version("2023.10")},
+		},
+	}
+
+	result := action.Execute(&pkg)
+
+	assert.Equal(t, ActionFailedStatus, result.GetStatus())
+	assert.ErrorIs(t, result.GetError(), ErrCheckAction)
+	assert.ErrorContains(t, result.GetError(), "invalid epoch 'x'")
+}
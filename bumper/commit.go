@@ -3,14 +3,28 @@ package bumper
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+	"unicode"
 
 	"github.com/bcyran/bumper/pack"
 	"go.uber.org/config"
 )
 
-const expectedGitStatus = " M .SRCINFO\x00 M PKGBUILD\x00"
+const (
+	defaultCommitSubject = "Bump version to {{.NewVersion}}"
+	// defaultReleaseNotesLength is the number of characters of the release notes available in the templates
+	defaultReleaseNotesLength = 1000
+)
+
+var (
+	ErrCommitAction = errors.New("commit action error")
 
-var ErrCommitAction = errors.New("commit action error")
+	// bumperFiles are the files changed by bumper itself, which are always committed
+	bumperFiles = []string{"PKGBUILD", ".SRCINFO"}
+)
 
 type commitActionResult struct {
 	BaseActionResult
@@ -28,11 +42,46 @@ func (result *commitActionResult) String() string {
 
 type CommitAction struct {
 	commandRunner CommandRunner
-	commitConfig  config.Value
+	// envCommandRunner runs the commit if the committer is configured, it can only be set through the environment
+	envCommandRunner EnvCommandRunner
+	commitConfig     config.Value
+	// signingErrs are set in Prepare, keyed by the package paths, if commits should be signed,
+	// but the signing key is not available in the package repository
+	signingErrs map[string]error
+	// gitRepos are git repositories of the packages, found in Prepare
+	gitRepos *GitRepos
 }
 
-func NewCommitAction(commandRunner CommandRunner, commitConfig config.Value) *CommitAction {
-	return &CommitAction{commandRunner: commandRunner, commitConfig: commitConfig}
+func NewCommitAction(commandRunner CommandRunner, envCommandRunner EnvCommandRunner, gitRepos *GitRepos, commitConfig config.Value) *CommitAction {
+	return &CommitAction{commandRunner: commandRunner, envCommandRunner: envCommandRunner, gitRepos: gitRepos, commitConfig: commitConfig}
+}
+
+func (action *CommitAction) commitsChanges() {}
+
+// Prepare finds git repositories of the packages and checks whether the signing key is available,
+// if commits should be signed. It's checked once per repository, as each one can configure signing differently,
+// so no package of the repository gets committed if its commits can't be signed.
+func (action *CommitAction) Prepare(pkgs []pack.Package) {
+	action.gitRepos.find(pkgs)
+	if !action.shouldSign() {
+		return
+	}
+
+	action.signingErrs = map[string]error{}
+	repoSigningErrs := map[*gitRepo]error{}
+	for _, pkg := range pkgs {
+		repo, isRepoKnown := action.gitRepos.get(pkg.Path)
+		if !isRepoKnown {
+			action.signingErrs[pkg.Path] = action.checkSigningKey(pkg.Path)
+			continue
+		}
+		signingErr, isChecked := repoSigningErrs[repo.gitRepo]
+		if !isChecked {
+			signingErr = action.checkSigningKey(repo.toplevel)
+			repoSigningErrs[repo.gitRepo] = signingErr
+		}
+		action.signingErrs[pkg.Path] = signingErr
+	}
 }
 
 func (action *CommitAction) Execute(pkg *pack.Package) ActionResult {
@@ -43,18 +92,32 @@ func (action *CommitAction) Execute(pkg *pack.Package) ActionResult {
 		return actionResult
 	}
 
-	isChanged, err := action.isChanged(pkg)
+	if signingErr := action.signingErrs[pkg.Path]; signingErr != nil {
+		actionResult.Status = ActionFailedStatus
+		actionResult.Error = fmt.Errorf("%w: %w", ErrCommitAction, signingErr)
+		return actionResult
+	}
+
+	repo, isRepoKnown := action.gitRepos.get(pkg.Path)
+	if isRepoKnown {
+		repo.mtx.Lock()
+		defer repo.mtx.Unlock()
+	}
+
+	files, hasOtherChanges, err := action.changedFiles(pkg, repo)
 	if err != nil {
 		actionResult.Status = ActionFailedStatus
 		actionResult.Error = fmt.Errorf("%w: %w", ErrCommitAction, err)
 		return actionResult
 	}
-	if !isChanged {
+	if len(files) == 0 {
 		actionResult.Status = ActionSkippedStatus
 		return actionResult
 	}
 
-	if err := action.commit(pkg); err != nil {
+	// in a shared repository the commit is limited to the package files, in case other packages have staged changes
+	isLimited := hasOtherChanges || (isRepoKnown && repo.isShared())
+	if err := action.commit(pkg, files, isLimited); err != nil {
 		actionResult.Status = ActionFailedStatus
 		actionResult.Error = fmt.Errorf("%w: %w", ErrCommitAction, err)
 		return actionResult
@@ -64,31 +127,110 @@ func (action *CommitAction) Execute(pkg *pack.Package) ActionResult {
 	return actionResult
 }
 
-func (action *CommitAction) isChanged(pkg *pack.Package) (bool, error) {
-	gitStatus, err := action.commandRunner(pkg.Path, "git", "status", "--porcelain", "--null", "--untracked-files=no")
+// changedFiles returns the changed files which should be committed: PKGBUILD, .SRCINFO and files allowed in the config.
+// Other changes make it fail, unless they are configured to be ignored, in which case hasOtherChanges is set.
+// Untracked files are never considered.
+// If the repository of the package is known, only the changes in the package directory are considered.
+func (action *CommitAction) changedFiles(pkg *pack.Package, repo packageRepo) ([]string, bool, error) {
+	statusArgs := []string{"status", "--porcelain", "--null", "--untracked-files=no"}
+	if repo.gitRepo != nil {
+		statusArgs = append(statusArgs, "--", ".")
+	}
+	gitStatus, err := action.commandRunner(pkg.Path, "git", statusArgs...)
 	if err != nil {
-		return false, err
+		return nil, false, err
+	}
+	statusEntries, err := parseGitStatus(gitStatus)
+	if err != nil {
+		return nil, false, err
 	}
 
-	if len(gitStatus) == 0 {
+	allowedPatterns := action.allowedFiles(pkg)
+	isAllowed := func(path string) (bool, error) {
+		if slices.Contains(bumperFiles, path) {
+			return true, nil
+		}
+		for _, pattern := range allowedPatterns {
+			if isMatch, err := filepath.Match(pattern, path); err != nil || isMatch {
+				return isMatch, err
+			}
+		}
 		return false, nil
 	}
 
-	if string(gitStatus) != expectedGitStatus {
-		return false, errors.New("unexpected changes in the repository")
+	files := []string{}
+	otherChanges := []string{}
+	for _, entry := range statusEntries {
+		if entry.isUnmerged() {
+			return nil, false, fmt.Errorf("unmerged changes in the repository: %s", entry.path)
+		}
+		entryPaths := []string{entry.path}
+		if entry.origPath != "" {
+			entryPaths = append(entryPaths, entry.origPath)
+		}
+		isEntryAllowed := true
+		for i, path := range entryPaths {
+			// paths are relative to the repository root, while the config refers to the package files
+			path, isInPackage := repo.packagePath(path)
+			entryPaths[i] = path
+			isPathAllowed, err := isAllowed(path)
+			if err != nil {
+				return nil, false, fmt.Errorf("invalid allowed files pattern: %w", err)
+			}
+			isEntryAllowed = isEntryAllowed && isInPackage && isPathAllowed
+		}
+		if !isEntryAllowed {
+			otherChanges = append(otherChanges, entry.path)
+			continue
+		}
+		for _, path := range entryPaths {
+			if !slices.Contains(bumperFiles, path) {
+				files = append(files, path)
+			}
+		}
+	}
+
+	if len(otherChanges) > 0 && !action.ignoreOtherChanges() {
+		return nil, false, fmt.Errorf("unexpected changes in the repository: %s", strings.Join(otherChanges, ", "))
 	}
+	if len(statusEntries) == len(otherChanges) {
+		return nil, false, nil
+	}
+	return append(slices.Clone(bumperFiles), files...), len(otherChanges) > 0, nil
+}
 
-	return true, nil
+// allowedFiles returns glob patterns of the files which may be committed along with PKGBUILD and .SRCINFO,
+// both global and specific to the package.
+func (action *CommitAction) allowedFiles(pkg *pack.Package) []string {
+	var allowedFiles, packageAllowedFiles []string
+	action.commitConfig.Get("allowedFiles").Populate(&allowedFiles)                                         // nolint:errcheck
+	action.commitConfig.Get("packages").Get(pkg.Pkgbase).Get("allowedFiles").Populate(&packageAllowedFiles) // nolint:errcheck
+	return append(allowedFiles, packageAllowedFiles...)
 }
 
-func (action *CommitAction) commit(pkg *pack.Package) error {
-	_, err := action.commandRunner(pkg.Path, "git", "add", "PKGBUILD", ".SRCINFO")
+func (action *CommitAction) ignoreOtherChanges() bool {
+	var ignoreOtherChanges bool
+	action.commitConfig.Get("ignoreOtherChanges").Populate(&ignoreOtherChanges) // nolint:errcheck
+	return ignoreOtherChanges
+}
+
+// commit commits the given files. If isLimited is set, the commit is limited to the given files,
+// so other changes in the repository are left alone even if they are staged.
+func (action *CommitAction) commit(pkg *pack.Package, files []string, isLimited bool) error {
+	subject, body, err := commitMessage(pkg, action.commitConfig.Get("message"))
 	if err != nil {
 		return err
 	}
 
-	commitMessage := fmt.Sprintf("Bump version to %s", pkg.UpstreamVersion)
-	commitArgs := []string{"commit", "--message", commitMessage}
+	_, err = action.commandRunner(pkg.Path, "git", append([]string{"add"}, files...)...)
+	if err != nil {
+		return err
+	}
+
+	commitArgs := []string{"commit", "--message", subject}
+	if body != "" {
+		commitArgs = append(commitArgs, "--message", body)
+	}
 
 	var commitAuthor string
 	action.commitConfig.Get("author").Populate(&commitAuthor) // nolint:errcheck
@@ -96,6 +238,188 @@ func (action *CommitAction) commit(pkg *pack.Package) error {
 		commitArgs = append(commitArgs, "--author", commitAuthor)
 	}
 
-	_, err = action.commandRunner(pkg.Path, "git", commitArgs...)
+	if action.shouldSign() {
+		if signingKey := action.signingKey(); signingKey != "" {
+			commitArgs = append(commitArgs, "--gpg-sign="+signingKey)
+		} else {
+			commitArgs = append(commitArgs, "--gpg-sign")
+		}
+	}
+
+	var trailers []string
+	action.commitConfig.Get("trailers").Populate(&trailers) // nolint:errcheck
+	for _, trailer := range trailers {
+		commitArgs = append(commitArgs, "--trailer", trailer)
+	}
+
+	if isLimited {
+		commitArgs = append(append(commitArgs, "--"), files...)
+	}
+
+	if err := action.runCommit(pkg, commitArgs); err != nil {
+		// added files would stay staged otherwise, not matching the rolled back working tree
+		if _, resetErr := action.commandRunner(pkg.Path, "git", append([]string{"reset", "--quiet", "--"}, files...)...); resetErr != nil {
+			return errors.Join(err, fmt.Errorf("unstaging failed: %w", resetErr))
+		}
+		return err
+	}
+	return nil
+}
+
+func (action *CommitAction) runCommit(pkg *pack.Package, commitArgs []string) error {
+	if committerEnv := action.committerEnv(); len(committerEnv) > 0 {
+		_, err := action.envCommandRunner(pkg.Path, committerEnv, "git", commitArgs...)
+		return err
+	}
+	_, err := action.commandRunner(pkg.Path, "git", commitArgs...)
 	return err
 }
+
+// committerEnv returns environment variables setting the configured committer name and email.
+func (action *CommitAction) committerEnv() []string {
+	var committer struct {
+		Name  string `yaml:"name"`
+		Email string `yaml:"email"`
+	}
+	action.commitConfig.Get("committer").Populate(&committer) // nolint:errcheck
+
+	env := []string{}
+	if committer.Name != "" {
+		env = append(env, "GIT_COMMITTER_NAME="+committer.Name)
+	}
+	if committer.Email != "" {
+		env = append(env, "GIT_COMMITTER_EMAIL="+committer.Email)
+	}
+	return env
+}
+
+func (action *CommitAction) shouldSign() bool {
+	var sign bool
+	action.commitConfig.Get("sign").Populate(&sign) // nolint:errcheck
+	return sign
+}
+
+func (action *CommitAction) signingKey() string {
+	var signingKey string
+	action.commitConfig.Get("signingKey").Populate(&signingKey) // nolint:errcheck
+	return signingKey
+}
+
+// checkSigningKey checks whether the configured signing key, or the one configured in git, is available.
+// Only OpenPGP keys are checked, other signature formats are assumed to be set up properly.
+func (action *CommitAction) checkSigningKey(cwd string) error {
+	// git config exits with error if the option is not set
+	gpgFormat, _ := action.commandRunner(cwd, "git", "config", "--get", "gpg.format")
+	if format := strings.TrimSpace(string(gpgFormat)); format != "" && format != "openpgp" {
+		return nil
+	}
+
+	signingKey := action.signingKey()
+	if signingKey == "" {
+		gitSigningKey, _ := action.commandRunner(cwd, "git", "config", "--get", "user.signingkey")
+		signingKey = strings.TrimSpace(string(gitSigningKey))
+	}
+
+	// git signs with the configured program, which may differ from gpg found in PATH
+	gpgProgram, _ := action.commandRunner(cwd, "git", "config", "--get", "gpg.program")
+	program := strings.TrimSpace(string(gpgProgram))
+	if program == "" {
+		program = "gpg"
+	}
+
+	gpgArgs := []string{"--list-secret-keys", "--with-colons"}
+	if signingKey != "" {
+		gpgArgs = append(gpgArgs, signingKey)
+	}
+	secretKeys, err := action.commandRunner(cwd, program, gpgArgs...)
+	if err != nil || !strings.Contains(string(secretKeys), "sec:") {
+		if signingKey == "" {
+			return errors.New("commits should be signed, but no secret key is available")
+		}
+		return fmt.Errorf("commits should be signed, but secret key %s is not available", signingKey)
+	}
+	return nil
+}
+
+// commitMessageValues holds the values available in commit message templates.
+type commitMessageValues struct {
+	Pkgbase    string
+	OldVersion string
+	NewVersion string
+	URL        string
+	Provider   string
+	// ReleaseNotes are truncated to the configured length, empty if the provider doesn't support them
+	ReleaseNotes string
+}
+
+func makeCommitMessageValues(pkg *pack.Package, releaseNotesLength int) commitMessageValues {
+	// upstream epoch is set only if it's bumped, otherwise the new version keeps the current one
+	newEpoch := pkg.UpstreamEpoch
+	if newEpoch == "" {
+		newEpoch = pkg.Epoch
+	}
+	return commitMessageValues{
+		Pkgbase:      pkg.Pkgbase,
+		OldVersion:   withEpoch(pkg.Epoch, pkg.Pkgver.GetVersionStr()),
+		NewVersion:   withEpoch(newEpoch, pkg.UpstreamVersion.GetVersionStr()),
+		URL:          pkg.URL,
+		Provider:     pkg.UpstreamProvider,
+		ReleaseNotes: truncateReleaseNotes(pkg.UpstreamReleaseNotes, releaseNotesLength),
+	}
+}
+
+// commitMessage returns subject and body of the commit message for the package.
+// Messages of version bumps are rendered from the subject and body templates configured in the message config,
+// rebuilds always have fixed messages.
+func commitMessage(pkg *pack.Package, messageConfig config.Value) (string, string, error) {
+	if pkg.IsRebuild {
+		if pkg.RebuildAgainst != "" {
+			return fmt.Sprintf("Rebuild against %s", pkg.RebuildAgainst), "", nil
+		}
+		return "Rebuild", "", nil
+	}
+
+	subjectTemplate := defaultCommitSubject
+	messageConfig.Get("subject").Populate(&subjectTemplate) // nolint:errcheck
+	var bodyTemplate string
+	messageConfig.Get("body").Populate(&bodyTemplate) // nolint:errcheck
+	releaseNotesLength := defaultReleaseNotesLength
+	messageConfig.Get("releaseNotesLength").Populate(&releaseNotesLength) // nolint:errcheck
+
+	values := makeCommitMessageValues(pkg, releaseNotesLength)
+	subject, err := renderMessageTemplate("subject", subjectTemplate, values)
+	if err != nil {
+		return "", "", err
+	}
+	if subject == "" {
+		return "", "", errors.New("commit message subject is empty")
+	}
+	body, err := renderMessageTemplate("body", bodyTemplate, values)
+	if err != nil {
+		return "", "", err
+	}
+	return subject, body, nil
+}
+
+func renderMessageTemplate(name string, templateStr string, values commitMessageValues) (string, error) {
+	messageTemplate, err := template.New(name).Option("missingkey=error").Parse(templateStr)
+	if err != nil {
+		return "", fmt.Errorf("invalid commit message %s template: %w", name, err)
+	}
+	messageBuilder := strings.Builder{}
+	if err := messageTemplate.Execute(&messageBuilder, values); err != nil {
+		return "", fmt.Errorf("invalid commit message %s template: %w", name, err)
+	}
+	return strings.TrimSpace(messageBuilder.String()), nil
+}
+
+// truncateReleaseNotes normalizes line endings of the release notes and shortens them to at most maxLength
+// characters, marking the cut with an ellipsis. Zero maxLength disables truncation.
+func truncateReleaseNotes(releaseNotes string, maxLength int) string {
+	releaseNotes = strings.TrimSpace(strings.ReplaceAll(releaseNotes, "\r\n", "\n"))
+	runes := []rune(releaseNotes)
+	if maxLength <= 0 || len(runes) <= maxLength {
+		return releaseNotes
+	}
+	return strings.TrimRightFunc(string(runes[:maxLength]), unicode.IsSpace) + "…"
+}
@@ -1,6 +1,7 @@
 package bumper
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
@@ -9,6 +10,7 @@ import (
 	"github.com/bcyran/bumper/pack"
 	"github.com/bcyran/bumper/upstream"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/config"
 )
 
@@ -18,10 +20,25 @@ var (
 	commitConfigWithAuthor  = commitConfigProvider.Get("commit")
 )
 
+func testCommitSrcinfo() *pack.Srcinfo {
+	return &pack.Srcinfo{
+		Pkgbase:     "foo",
+		URL:         "https://foo.bar",
+		FullVersion: &pack.FullVersion{Pkgver: pack.Version("1.0.0"), Pkgrel: "1"},
+	}
+}
+
+func testCommitSrcinfoWithEpoch(epoch string) *pack.Srcinfo {
+	srcinfo := testCommitSrcinfo()
+	srcinfo.Epoch = epoch
+	return srcinfo
+}
+
 func TestCommitAction_Success(t *testing.T) {
 	// our Package struct
 	pkg := &pack.Package{
 		Path:            "/foo/bar/baz",
+		Srcinfo:         testCommitSrcinfo(),
 		UpstreamVersion: upstream.Version("1.2.3"),
 		IsOutdated:      true,
 	}
@@ -35,7 +52,7 @@ func TestCommitAction_Success(t *testing.T) {
 	fakeCommandRunner, commandRuns := testutils.MakeFakeCommandRunner(&commandRetvals)
 
 	// execute the action with our mocked command runner
-	action := NewCommitAction(fakeCommandRunner, emptyCommitConfig)
+	action := NewCommitAction(fakeCommandRunner, nil, NewGitRepos(fakeCommandRunner), emptyCommitConfig)
 	result := action.Execute(pkg)
 
 	// result assertions
@@ -66,6 +83,7 @@ func TestCommitAction_SuccessWithAuthor(t *testing.T) {
 	// our Package struct
 	pkg := &pack.Package{
 		Path:            "/foo/bar/baz",
+		Srcinfo:         testCommitSrcinfo(),
 		UpstreamVersion: upstream.Version("1.2.3"),
 		IsOutdated:      true,
 	}
@@ -79,7 +97,7 @@ func TestCommitAction_SuccessWithAuthor(t *testing.T) {
 	fakeCommandRunner, commandRuns := testutils.MakeFakeCommandRunner(&commandRetvals)
 
 	// execute the action with our mocked command runner
-	action := NewCommitAction(fakeCommandRunner, commitConfigWithAuthor)
+	action := NewCommitAction(fakeCommandRunner, nil, NewGitRepos(fakeCommandRunner), commitConfigWithAuthor)
 	result := action.Execute(pkg)
 
 	// result assertions
@@ -98,6 +116,7 @@ func TestCommitAction_Skip(t *testing.T) {
 	// our Package struct
 	pkg := &pack.Package{
 		Path:            "/foo/bar/baz",
+		Srcinfo:         testCommitSrcinfo(),
 		UpstreamVersion: upstream.Version("1.2.3"),
 		IsOutdated:      true,
 	}
@@ -109,7 +128,7 @@ func TestCommitAction_Skip(t *testing.T) {
 	fakeCommandRunner, _ := testutils.MakeFakeCommandRunner(&commandRetvals)
 
 	// execute the action with our mocked command runner
-	action := NewCommitAction(fakeCommandRunner, emptyCommitConfig)
+	action := NewCommitAction(fakeCommandRunner, nil, NewGitRepos(fakeCommandRunner), emptyCommitConfig)
 	result := action.Execute(pkg)
 
 	assert.Equal(t, ActionSkippedStatus, result.GetStatus())
@@ -120,6 +139,7 @@ func TestCommitAction_Fail(t *testing.T) {
 	// our Package struct
 	pkg := &pack.Package{
 		Path:            "/foo/bar/baz",
+		Srcinfo:         testCommitSrcinfo(),
 		UpstreamVersion: upstream.Version("1.2.3"),
 		IsOutdated:      true,
 	}
@@ -131,7 +151,7 @@ func TestCommitAction_Fail(t *testing.T) {
 	fakeCommandRunner, _ := testutils.MakeFakeCommandRunner(&commandRetvals)
 
 	// execute the action with our mocked command runner
-	action := NewCommitAction(fakeCommandRunner, emptyCommitConfig)
+	action := NewCommitAction(fakeCommandRunner, nil, NewGitRepos(fakeCommandRunner), emptyCommitConfig)
 	result := action.Execute(pkg)
 
 	assert.Equal(t, ActionFailedStatus, result.GetStatus())
@@ -139,3 +159,403 @@ func TestCommitAction_Fail(t *testing.T) {
 	assert.ErrorContains(t, result.GetError(), "unexpected changes in the repository")
 	assert.ErrorContains(t, result.GetError(), "commit action error")
 }
+
+func TestCommitMessage(t *testing.T) {
+	cases := map[string]pack.Package{
+		"Bump version to 1.2.3":  {Srcinfo: testCommitSrcinfo(), UpstreamVersion: upstream.Version("1.2.3")},
+		"Bump version to 2:1.0":  {Srcinfo: testCommitSrcinfo(), UpstreamVersion: upstream.Version("1.0"), UpstreamEpoch: "2"},
+		"Bump version to 1:1.1":  {Srcinfo: testCommitSrcinfoWithEpoch("1"), UpstreamVersion: upstream.Version("1.1")},
+		"Rebuild against python": {Srcinfo: testCommitSrcinfo(), IsRebuild: true, RebuildAgainst: "python"},
+		"Rebuild":                {Srcinfo: testCommitSrcinfo(), IsRebuild: true},
+	}
+
+	for expectedMessage, pkg := range cases {
+		subject, body, err := commitMessage(&pkg, emptyCommitConfig)
+		assert.NoError(t, err)
+		assert.Equal(t, expectedMessage, subject)
+		assert.Empty(t, body)
+	}
+}
+
+func TestCommitMessage_Templates(t *testing.T) {
+	messageConfig := commitMessageConfig(t, `{
+		subject: "{{.Pkgbase}}: {{.OldVersion}} -> {{.NewVersion}}",
+		body: "Found by {{.Provider}} at {{.URL}}\n\n{{.ReleaseNotes}}",
+		releaseNotesLength: 10,
+	}`)
+	pkg := pack.Package{
+		Srcinfo:              testCommitSrcinfo(),
+		UpstreamVersion:      upstream.Version("1.2.3"),
+		UpstreamProvider:     "github",
+		UpstreamReleaseNotes: "Fixed bugs\r\nand added features\r\n",
+	}
+
+	subject, body, err := commitMessage(&pkg, messageConfig)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "foo: 1.0.0 -> 1.2.3", subject)
+	assert.Equal(t, "Found by github at https://foo.bar\n\nFixed bugs…", body)
+}
+
+func TestCommitMessage_InvalidTemplates(t *testing.T) {
+	cases := map[string]string{
+		`{subject: "{{.Nope}}"}`:                 "invalid commit message subject template",
+		`{subject: "{{.NewVersion"}`:             "invalid commit message subject template",
+		`{subject: "{{if false}}x{{end}}"}`:      "commit message subject is empty",
+		`{body: "{{range .NewVersion}}{{end}}"}`: "invalid commit message body template",
+	}
+
+	for messageConfigStr, expectedErr := range cases {
+		pkg := pack.Package{Srcinfo: testCommitSrcinfo(), UpstreamVersion: upstream.Version("1.2.3")}
+
+		_, _, err := commitMessage(&pkg, commitMessageConfig(t, messageConfigStr))
+
+		assert.ErrorContains(t, err, expectedErr, messageConfigStr)
+	}
+}
+
+func TestCommitAction_MessageBody(t *testing.T) {
+	pkg := &pack.Package{
+		Path:                 "/foo/bar/baz",
+		Srcinfo:              testCommitSrcinfo(),
+		UpstreamVersion:      upstream.Version("1.2.3"),
+		UpstreamReleaseNotes: "Fixed bugs",
+		IsOutdated:           true,
+	}
+	commandRetvals := []testutils.CommandRunnerRetval{
+		{Stdout: []byte(" M .SRCINFO\x00 M PKGBUILD\x00"), Err: nil}, // git status
+		{Stdout: []byte{}, Err: nil},                                 // git add
+		{Stdout: []byte{}, Err: nil},                                 // git commit
+	}
+	fakeCommandRunner, commandRuns := testutils.MakeFakeCommandRunner(&commandRetvals)
+	commitConfig := commitMessageConfig(t, `{message: {body: "{{.ReleaseNotes}}"}}`)
+
+	action := NewCommitAction(fakeCommandRunner, nil, NewGitRepos(fakeCommandRunner), commitConfig)
+	result := action.Execute(pkg)
+
+	assert.Equal(t, ActionSuccessStatus, result.GetStatus())
+	expectedCommitCommand := testutils.CommandRunnerParams{
+		Cwd: pkg.Path, Command: "git", Args: []string{"commit", "--message", "Bump version to 1.2.3", "--message", "Fixed bugs"},
+	}
+	assert.Equal(t, expectedCommitCommand, (*commandRuns)[2])
+}
+
+func commitMessageConfig(t *testing.T, messageConfig string) config.Value {
+	configProvider, err := config.NewYAML(config.Source(strings.NewReader(messageConfig)))
+	require.NoError(t, err)
+	return configProvider.Get(config.Root)
+}
+
+func TestCommitAction_SigningAndCommitter(t *testing.T) {
+	pkg := &pack.Package{
+		Path:            "/foo/bar/baz",
+		Srcinfo:         testCommitSrcinfo(),
+		UpstreamVersion: upstream.Version("1.2.3"),
+		IsOutdated:      true,
+	}
+	commandRetvals := []testutils.CommandRunnerRetval{
+		{Stdout: []byte(" M .SRCINFO\x00 M PKGBUILD\x00"), Err: nil}, // git status
+		{Stdout: []byte{}, Err: nil},                                 // git add
+		{Stdout: []byte{}, Err: nil},                                 // git commit
+	}
+	fakeCommandRunner, fakeEnvCommandRunner, commandRuns := testutils.MakeFakeCommandRunners(&commandRetvals)
+	commitConfig := commitMessageConfig(t, `{
+		sign: true,
+		signingKey: ABCD1234,
+		committer: {name: Bot, email: bot@example.com},
+		trailers: ["Signed-off-by: John Doe <john.doe@example.com>"],
+	}`)
+
+	action := NewCommitAction(fakeCommandRunner, fakeEnvCommandRunner, NewGitRepos(fakeCommandRunner), commitConfig)
+	result := action.Execute(pkg)
+
+	assert.Equal(t, ActionSuccessStatus, result.GetStatus())
+	expectedCommitCommand := testutils.CommandRunnerParams{
+		Cwd: pkg.Path, Env: []string{"GIT_COMMITTER_NAME=Bot", "GIT_COMMITTER_EMAIL=bot@example.com"}, Command: "git", Args: []string{
+			"commit", "--message", "Bump version to 1.2.3", "--gpg-sign=ABCD1234",
+			"--trailer", "Signed-off-by: John Doe <john.doe@example.com>",
+		},
+	}
+	assert.Equal(t, expectedCommitCommand, (*commandRuns)[2])
+}
+
+func TestCommitAction_PrepareSigningKey(t *testing.T) {
+	pkgs := []pack.Package{{Path: "/foo/bar/baz"}}
+	cases := map[string]struct {
+		commitConfig     string
+		commandRetvals   []testutils.CommandRunnerRetval
+		expectedCommands [][]string
+		expectedErr      string
+	}{
+		"configured key available": {
+			commitConfig: "{sign: true, signingKey: ABCD1234}",
+			commandRetvals: []testutils.CommandRunnerRetval{
+				{Stdout: []byte{}, Err: errors.New("exit status 1")}, // git config gpg.format
+				{Stdout: []byte{}, Err: errors.New("exit status 1")}, // git config gpg.program
+				{Stdout: []byte("sec:u:255:22:ABCD1234:\n"), Err: nil},
+			},
+			expectedCommands: [][]string{
+				{"git", "config", "--get", "gpg.format"},
+				{"git", "config", "--get", "gpg.program"},
+				{"gpg", "--list-secret-keys", "--with-colons", "ABCD1234"},
+			},
+		},
+		"configured gpg program": {
+			commitConfig: "{sign: true, signingKey: ABCD1234}",
+			commandRetvals: []testutils.CommandRunnerRetval{
+				{Stdout: []byte{}, Err: errors.New("exit status 1")},
+				{Stdout: []byte("gpg2\n"), Err: nil},
+				{Stdout: []byte("sec:u:255:22:ABCD1234:\n"), Err: nil},
+			},
+			expectedCommands: [][]string{
+				{"git", "config", "--get", "gpg.format"},
+				{"git", "config", "--get", "gpg.program"},
+				{"gpg2", "--list-secret-keys", "--with-colons", "ABCD1234"},
+			},
+		},
+		"git key missing": {
+			commitConfig: "{sign: true}",
+			commandRetvals: []testutils.CommandRunnerRetval{
+				{Stdout: []byte("openpgp\n"), Err: nil},
+				{Stdout: []byte("EFGH5678\n"), Err: nil},
+				{Stdout: []byte{}, Err: errors.New("exit status 1")},
+				{Stdout: []byte{}, Err: errors.New("exit status 2")},
+			},
+			expectedCommands: [][]string{
+				{"git", "config", "--get", "gpg.format"},
+				{"git", "config", "--get", "user.signingkey"},
+				{"git", "config", "--get", "gpg.program"},
+				{"gpg", "--list-secret-keys", "--with-colons", "EFGH5678"},
+			},
+			expectedErr: "secret key EFGH5678 is not available",
+		},
+		"no keys at all": {
+			commitConfig: "{sign: true}",
+			commandRetvals: []testutils.CommandRunnerRetval{
+				{Stdout: []byte{}, Err: errors.New("exit status 1")},
+				{Stdout: []byte{}, Err: errors.New("exit status 1")},
+				{Stdout: []byte{}, Err: errors.New("exit status 1")},
+				{Stdout: []byte{}, Err: nil},
+			},
+			expectedCommands: [][]string{
+				{"git", "config", "--get", "gpg.format"},
+				{"git", "config", "--get", "user.signingkey"},
+				{"git", "config", "--get", "gpg.program"},
+				{"gpg", "--list-secret-keys", "--with-colons"},
+			},
+			expectedErr: "no secret key is available",
+		},
+		"ssh format is not checked": {
+			commitConfig: "{sign: true}",
+			commandRetvals: []testutils.CommandRunnerRetval{
+				{Stdout: []byte("ssh\n"), Err: nil},
+			},
+			expectedCommands: [][]string{
+				{"git", "config", "--get", "gpg.format"},
+			},
+		},
+		"not signing": {
+			commitConfig: "{}",
+		},
+	}
+
+	for name, testCase := range cases {
+		t.Run(name, func(t *testing.T) {
+			// git repository is found first
+			commandRetvals := append([]testutils.CommandRunnerRetval{{Stdout: []byte("/foo/bar/baz\n\n"), Err: nil}}, testCase.commandRetvals...)
+			expectedCommands := append([][]string{{"git", "rev-parse", "--show-toplevel", "--show-prefix"}}, testCase.expectedCommands...)
+			fakeCommandRunner, commandRuns := testutils.MakeFakeCommandRunner(&commandRetvals)
+			action := NewCommitAction(fakeCommandRunner, nil, NewGitRepos(fakeCommandRunner), commitMessageConfig(t, testCase.commitConfig))
+
+			action.Prepare(pkgs)
+
+			actualCommands := [][]string{}
+			for _, commandRun := range *commandRuns {
+				assert.Equal(t, "/foo/bar/baz", commandRun.Cwd)
+				actualCommands = append(actualCommands, append([]string{commandRun.Command}, commandRun.Args...))
+			}
+			assert.Equal(t, expectedCommands, actualCommands)
+			if testCase.expectedErr == "" {
+				assert.NoError(t, action.signingErrs["/foo/bar/baz"])
+			} else {
+				assert.ErrorContains(t, action.signingErrs["/foo/bar/baz"], testCase.expectedErr)
+			}
+		})
+	}
+}
+
+func TestCommitAction_PrepareSigningKeyPerRepo(t *testing.T) {
+	pkgs := []pack.Package{{Path: "/repo-a/foo"}, {Path: "/repo-a/bar"}, {Path: "/repo-b/baz"}}
+	commandRetvals := []testutils.CommandRunnerRetval{
+		{Stdout: []byte("/repo-a\nfoo/\n"), Err: nil},        // git rev-parse of foo
+		{Stdout: []byte("/repo-a\nbar/\n"), Err: nil},        // git rev-parse of bar
+		{Stdout: []byte("/repo-b\nbaz/\n"), Err: nil},        // git rev-parse of baz
+		{Stdout: []byte("ssh\n"), Err: nil},                  // git config gpg.format in repo-a
+		{Stdout: []byte{}, Err: errors.New("exit status 1")}, // git config gpg.format in repo-b
+		{Stdout: []byte{}, Err: errors.New("exit status 1")}, // git config gpg.program in repo-b
+		{Stdout: []byte{}, Err: errors.New("exit status 2")}, // gpg --list-secret-keys in repo-b
+	}
+	fakeCommandRunner, commandRuns := testutils.MakeFakeCommandRunner(&commandRetvals)
+	action := NewCommitAction(fakeCommandRunner, nil, NewGitRepos(fakeCommandRunner), commitMessageConfig(t, "{sign: true, signingKey: ABCD1234}"))
+
+	action.Prepare(pkgs)
+
+	assert.Len(t, *commandRuns, 7)
+	assert.Equal(t, "/repo-a", (*commandRuns)[3].Cwd)
+	assert.Equal(t, "/repo-b", (*commandRuns)[4].Cwd)
+	assert.NoError(t, action.signingErrs["/repo-a/foo"])
+	assert.NoError(t, action.signingErrs["/repo-a/bar"])
+	assert.ErrorContains(t, action.signingErrs["/repo-b/baz"], "secret key ABCD1234 is not available")
+}
+
+func TestCommitAction_FailSigningKeyNotAvailable(t *testing.T) {
+	pkg := &pack.Package{
+		Path:            "/foo/bar/baz",
+		Srcinfo:         testCommitSrcinfo(),
+		UpstreamVersion: upstream.Version("1.2.3"),
+		IsOutdated:      true,
+	}
+	commandRetvals := []testutils.CommandRunnerRetval{}
+	fakeCommandRunner, _ := testutils.MakeFakeCommandRunner(&commandRetvals)
+	action := NewCommitAction(fakeCommandRunner, nil, NewGitRepos(fakeCommandRunner), commitMessageConfig(t, "{sign: true}"))
+	action.signingErrs = map[string]error{pkg.Path: errors.New("secret key ABCD1234 is not available")}
+
+	result := action.Execute(pkg)
+
+	assert.Equal(t, ActionFailedStatus, result.GetStatus())
+	assert.ErrorIs(t, result.GetError(), ErrCommitAction)
+	assert.ErrorContains(t, result.GetError(), "secret key ABCD1234 is not available")
+}
+
+func TestCommitAction_ChangedFiles(t *testing.T) {
+	cases := map[string]struct {
+		commitConfig  string
+		gitStatus     string
+		expectedFiles []string
+		expectedOther bool
+		expectedErr   string
+	}{
+		"only staged srcinfo": {
+			commitConfig:  "{}",
+			gitStatus:     "M  .SRCINFO\x00 M PKGBUILD\x00",
+			expectedFiles: []string{"PKGBUILD", ".SRCINFO"},
+		},
+		"allowed files": {
+			commitConfig:  "{allowedFiles: [.nvchecker.toml], packages: {foo: {allowedFiles: ['*.patch']}}}",
+			gitStatus:     " M .SRCINFO\x00 M PKGBUILD\x00 M fix.patch\x00 M .nvchecker.toml\x00R  new.patch\x00old.patch\x00",
+			expectedFiles: []string{"PKGBUILD", ".SRCINFO", "fix.patch", ".nvchecker.toml", "new.patch", "old.patch"},
+		},
+		"allowed files of other package": {
+			commitConfig: "{packages: {bar: {allowedFiles: ['*.patch']}}}",
+			gitStatus:    " M .SRCINFO\x00 M PKGBUILD\x00 M fix.patch\x00",
+			expectedErr:  "unexpected changes in the repository: fix.patch",
+		},
+		"ignored other changes": {
+			commitConfig:  "{ignoreOtherChanges: true}",
+			gitStatus:     " M .SRCINFO\x00 M PKGBUILD\x00A  foo.txt\x00",
+			expectedFiles: []string{"PKGBUILD", ".SRCINFO"},
+			expectedOther: true,
+		},
+		"only other changes": {
+			commitConfig: "{ignoreOtherChanges: true}",
+			gitStatus:    " M foo.txt\x00",
+		},
+		"unmerged": {
+			commitConfig: "{ignoreOtherChanges: true}",
+			gitStatus:    " M .SRCINFO\x00UU PKGBUILD\x00",
+			expectedErr:  "unmerged changes in the repository: PKGBUILD",
+		},
+		"invalid pattern": {
+			commitConfig: "{allowedFiles: ['[']}",
+			gitStatus:    " M foo.txt\x00",
+			expectedErr:  "invalid allowed files pattern",
+		},
+	}
+
+	for name, testCase := range cases {
+		t.Run(name, func(t *testing.T) {
+			pkg := &pack.Package{Path: "/foo/bar/baz", Srcinfo: testCommitSrcinfo()}
+			commandRetvals := []testutils.CommandRunnerRetval{{Stdout: []byte(testCase.gitStatus), Err: nil}}
+			fakeCommandRunner, _ := testutils.MakeFakeCommandRunner(&commandRetvals)
+			action := NewCommitAction(fakeCommandRunner, nil, NewGitRepos(fakeCommandRunner), commitMessageConfig(t, testCase.commitConfig))
+
+			files, hasOtherChanges, err := action.changedFiles(pkg, packageRepo{})
+
+			if testCase.expectedErr != "" {
+				assert.ErrorContains(t, err, testCase.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedFiles, files)
+			assert.Equal(t, testCase.expectedOther, hasOtherChanges)
+		})
+	}
+}
+
+func TestCommitAction_IgnoreOtherChanges(t *testing.T) {
+	pkg := &pack.Package{
+		Path:            "/foo/bar/baz",
+		Srcinfo:         testCommitSrcinfo(),
+		UpstreamVersion: upstream.Version("1.2.3"),
+		IsOutdated:      true,
+	}
+	commandRetvals := []testutils.CommandRunnerRetval{
+		{Stdout: []byte(" M .SRCINFO\x00 M PKGBUILD\x00 M fix.patch\x00M  foo.txt\x00"), Err: nil}, // git status
+		{Stdout: []byte{}, Err: nil}, // git add
+		{Stdout: []byte{}, Err: nil}, // git commit
+	}
+	fakeCommandRunner, commandRuns := testutils.MakeFakeCommandRunner(&commandRetvals)
+	commitConfig := commitMessageConfig(t, "{ignoreOtherChanges: true, allowedFiles: ['*.patch']}")
+
+	action := NewCommitAction(fakeCommandRunner, nil, NewGitRepos(fakeCommandRunner), commitConfig)
+	result := action.Execute(pkg)
+
+	assert.Equal(t, ActionSuccessStatus, result.GetStatus())
+	expectedAddCommand := testutils.CommandRunnerParams{
+		Cwd: pkg.Path, Command: "git", Args: []string{"add", "PKGBUILD", ".SRCINFO", "fix.patch"},
+	}
+	assert.Equal(t, expectedAddCommand, (*commandRuns)[1])
+	// commit is limited to the added files, so the staged foo.txt is not committed
+	expectedCommitCommand := testutils.CommandRunnerParams{
+		Cwd: pkg.Path, Command: "git", Args: []string{"commit", "--message", "Bump version to 1.2.3", "--", "PKGBUILD", ".SRCINFO", "fix.patch"},
+	}
+	assert.Equal(t, expectedCommitCommand, (*commandRuns)[2])
+}
+
+func TestCommitAction_SharedRepository(t *testing.T) {
+	pkg := pack.Package{
+		Path:            "/repo/foo",
+		Srcinfo:         testCommitSrcinfo(),
+		UpstreamVersion: upstream.Version("1.2.3"),
+		IsOutdated:      true,
+	}
+	commandRetvals := []testutils.CommandRunnerRetval{
+		{Stdout: []byte("/repo\nfoo/\n"), Err: nil},                                              // git rev-parse of foo
+		{Stdout: []byte("/repo\nbar/\n"), Err: nil},                                              // git rev-parse of bar
+		{Stdout: []byte(" M foo/.SRCINFO\x00 M foo/PKGBUILD\x00 M foo/fix.patch\x00"), Err: nil}, // git status
+		{Stdout: []byte{}, Err: nil},                                                             // git add
+		{Stdout: []byte{}, Err: nil},                                                             // git commit
+	}
+	fakeCommandRunner, commandRuns := testutils.MakeFakeCommandRunner(&commandRetvals)
+	commitConfig := commitMessageConfig(t, "{packages: {foo: {allowedFiles: ['*.patch']}}}")
+
+	action := NewCommitAction(fakeCommandRunner, nil, NewGitRepos(fakeCommandRunner), commitConfig)
+	action.Prepare([]pack.Package{pkg, {Path: "/repo/bar"}})
+	result := action.Execute(&pkg)
+
+	assert.Equal(t, ActionSuccessStatus, result.GetStatus())
+	// status is limited to the package directory
+	expectedStatusCommand := testutils.CommandRunnerParams{
+		Cwd: pkg.Path, Command: "git", Args: []string{"status", "--porcelain", "--null", "--untracked-files=no", "--", "."},
+	}
+	assert.Equal(t, expectedStatusCommand, (*commandRuns)[2])
+	expectedAddCommand := testutils.CommandRunnerParams{
+		Cwd: pkg.Path, Command: "git", Args: []string{"add", "PKGBUILD", ".SRCINFO", "fix.patch"},
+	}
+	assert.Equal(t, expectedAddCommand, (*commandRuns)[3])
+	// commit is limited to the package files, other packages might have staged changes
+	expectedCommitCommand := testutils.CommandRunnerParams{
+		Cwd: pkg.Path, Command: "git", Args: []string{"commit", "--message", "Bump version to 1.2.3", "--", "PKGBUILD", ".SRCINFO", "fix.patch"},
+	}
+	assert.Equal(t, expectedCommitCommand, (*commandRuns)[4])
+}
@@ -3,15 +3,27 @@ package bumper
 import (
 	"bytes"
 	"fmt"
+	"os"
 	"os/exec"
 	"strings"
 )
 
 type CommandRunner = func(cwd string, command string, args ...string) ([]byte, error)
 
+// EnvCommandRunner is a CommandRunner which also adds the given variables to the environment of the command.
+type EnvCommandRunner = func(cwd string, env []string, command string, args ...string) ([]byte, error)
+
 func ExecCommand(cwd string, command string, args ...string) ([]byte, error) {
+	return ExecCommandWithEnv(cwd, nil, command, args...)
+}
+
+// ExecCommandWithEnv is ExecCommand with the given "KEY=value" variables added to the environment.
+func ExecCommandWithEnv(cwd string, env []string, command string, args ...string) ([]byte, error) {
 	cmd := exec.Command(command, args...)
 	cmd.Dir = cwd
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
 	stdoutBuf := bytes.Buffer{}
 	stderrBuf := strings.Builder{}
 	cmd.Stdout = &stdoutBuf
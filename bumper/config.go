@@ -12,6 +12,7 @@ import (
 const (
 	defaultConfigDir = ".config"
 	relConfigPath    = "bumper/config.yaml"
+	relPluginsDir    = "bumper/providers"
 )
 
 var (
@@ -48,14 +49,24 @@ func ReadConfig(requestedPath string, overrides ...config.YAMLOption) (config.Pr
 	return config.NewYAML(configSources...)
 }
 
+// GetPluginsDir returns path of the directory containing version provider plugins.
+func GetPluginsDir() (string, error) {
+	return getConfigHomePath(relPluginsDir)
+}
+
 func getConfigPath() (string, error) {
+	return getConfigHomePath(relConfigPath)
+}
+
+// getConfigHomePath joins the relative path with the user config directory.
+func getConfigHomePath(relPath string) (string, error) {
 	configHome, configHomeSet := os.LookupEnv("XDG_CONFIG_HOME")
 	if configHomeSet {
-		return filepath.Join(configHome, relConfigPath), nil
+		return filepath.Join(configHome, relPath), nil
 	}
 	userHome, userHomeSet := os.LookupEnv("HOME")
 	if userHomeSet {
-		return filepath.Join(userHome, defaultConfigDir, relConfigPath), nil
+		return filepath.Join(userHome, defaultConfigDir, relPath), nil
 	}
 	return "", ErrUnknownConfigPath
 }
@@ -68,3 +68,12 @@ func TestReadConfig_DefaultNoPath(t *testing.T) {
 
 	assert.ErrorIs(t, err, ErrUnknownConfigPath)
 }
+
+func TestGetPluginsDir(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/config/home")
+
+	pluginsDir, err := GetPluginsDir()
+
+	assert.Nil(t, err)
+	assert.Equal(t, "/config/home/bumper/providers", pluginsDir)
+}
@@ -0,0 +1,112 @@
+package bumper
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/bcyran/bumper/pack"
+)
+
+// writeFileAtomic writes data to a temporary file and renames it to the given path,
+// so the file is never left partially written. Mode of the existing file is kept, perm is used for new files.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmpFile.Name()
+	// Removing fails harmlessly if the file has been renamed
+	defer os.Remove(tmpPath)
+
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Chmod(perm); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// fileSnapshot holds contents of the files, to restore them later.
+type fileSnapshot struct {
+	// files maps paths to contents, nil if the file didn't exist
+	files map[string][]byte
+}
+
+// takeSnapshot reads the files at the given paths, files which don't exist are also recorded.
+func takeSnapshot(paths ...string) (*fileSnapshot, error) {
+	snapshot := fileSnapshot{files: map[string][]byte{}}
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		snapshot.files[path] = content
+	}
+	return &snapshot, nil
+}
+
+// restore writes back the snapshot contents, files which didn't exist are removed.
+func (snapshot *fileSnapshot) restore() error {
+	restoreErrs := []error{}
+	for path, content := range snapshot.files {
+		if content == nil {
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				restoreErrs = append(restoreErrs, err)
+			}
+			continue
+		}
+		if err := writeFileAtomic(path, content, 0o644); err != nil {
+			restoreErrs = append(restoreErrs, err)
+		}
+	}
+	return errors.Join(restoreErrs...)
+}
+
+// packageSnapshots holds snapshots of PKGBUILD and .SRCINFO taken before changing the packages, keyed by package path.
+type packageSnapshots struct {
+	snapshots map[string]*fileSnapshot
+	mtx       sync.Mutex
+}
+
+func (snapshots *packageSnapshots) take(pkg *pack.Package) error {
+	snapshot, err := takeSnapshot(pkg.PkgbuildPath(), pkg.SrcinfoPath())
+	if err != nil {
+		return fmt.Errorf("package files reading error: %w", err)
+	}
+	snapshots.mtx.Lock()
+	defer snapshots.mtx.Unlock()
+	if snapshots.snapshots == nil {
+		snapshots.snapshots = map[string]*fileSnapshot{}
+	}
+	snapshots.snapshots[pkg.Path] = snapshot
+	return nil
+}
+
+// restore restores the package files from the snapshot, if it's taken, and forgets it.
+// With keep set, the snapshot is just forgotten.
+func (snapshots *packageSnapshots) restore(pkg *pack.Package, keep bool) error {
+	snapshots.mtx.Lock()
+	snapshot, isTaken := snapshots.snapshots[pkg.Path]
+	delete(snapshots.snapshots, pkg.Path)
+	snapshots.mtx.Unlock()
+
+	if !isTaken || keep {
+		return nil
+	}
+	return snapshot.restore()
+}
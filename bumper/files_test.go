@@ -0,0 +1,50 @@
+package bumper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	existingPath := filepath.Join(dir, "PKGBUILD")
+	require.NoError(t, os.WriteFile(existingPath, []byte("old"), 0o600))
+	newPath := filepath.Join(dir, ".SRCINFO")
+
+	assert.NoError(t, writeFileAtomic(existingPath, []byte("new"), 0o644))
+	assert.NoError(t, writeFileAtomic(newPath, []byte("created"), 0o640))
+
+	content, _ := os.ReadFile(existingPath)
+	assert.Equal(t, "new", string(content))
+	info, _ := os.Stat(existingPath)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	info, _ = os.Stat(newPath)
+	assert.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+	// no temporary files left
+	entries, _ := os.ReadDir(dir)
+	assert.Len(t, entries, 2)
+}
+
+func TestFileSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	existingPath := filepath.Join(dir, "PKGBUILD")
+	require.NoError(t, os.WriteFile(existingPath, []byte("before"), 0o755))
+	missingPath := filepath.Join(dir, ".SRCINFO")
+
+	snapshot, err := takeSnapshot(existingPath, missingPath)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(existingPath, []byte("after"), 0o755))
+	require.NoError(t, os.WriteFile(missingPath, []byte("after"), 0o644))
+
+	assert.NoError(t, snapshot.restore())
+
+	content, _ := os.ReadFile(existingPath)
+	assert.Equal(t, "before", string(content))
+	info, _ := os.Stat(existingPath)
+	assert.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+	assert.NoFileExists(t, missingPath)
+}
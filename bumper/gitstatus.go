@@ -0,0 +1,50 @@
+package bumper
+
+import (
+	"fmt"
+	"strings"
+)
+
+// gitStatusEntry is a single changed file reported by git status.
+type gitStatusEntry struct {
+	// status is the two letter XY code, X is the status in the index, Y in the working tree
+	status string
+	path   string
+	// origPath is the path the file was renamed or copied from, empty for other changes
+	origPath string
+}
+
+func (entry *gitStatusEntry) isUnmerged() bool {
+	switch entry.status {
+	case "DD", "AU", "UD", "UA", "DU", "AA", "UU":
+		return true
+	default:
+		return false
+	}
+}
+
+// parseGitStatus parses the output of 'git status --porcelain --null'.
+func parseGitStatus(gitStatus []byte) ([]gitStatusEntry, error) {
+	entries := []gitStatusEntry{}
+	fields := strings.Split(string(gitStatus), "\x00")
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		if field == "" {
+			continue
+		}
+		if len(field) < 4 || field[2] != ' ' {
+			return nil, fmt.Errorf("invalid git status entry '%s'", field)
+		}
+		entry := gitStatusEntry{status: field[:2], path: field[3:]}
+		// renamed and copied entries are followed by the original path
+		if entry.status[0] == 'R' || entry.status[0] == 'C' {
+			if i+1 >= len(fields) || fields[i+1] == "" {
+				return nil, fmt.Errorf("missing original path of git status entry '%s'", field)
+			}
+			i++
+			entry.origPath = fields[i]
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
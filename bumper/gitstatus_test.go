@@ -0,0 +1,39 @@
+package bumper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGitStatus(t *testing.T) {
+	gitStatus := []byte(" M PKGBUILD\x00M  .SRCINFO\x00R  new.patch\x00old.patch\x00A  file with spaces.txt\x00UU conflict.txt\x00")
+
+	entries, err := parseGitStatus(gitStatus)
+
+	assert.NoError(t, err)
+	expectedEntries := []gitStatusEntry{
+		{status: " M", path: "PKGBUILD"},
+		{status: "M ", path: ".SRCINFO"},
+		{status: "R ", path: "new.patch", origPath: "old.patch"},
+		{status: "A ", path: "file with spaces.txt"},
+		{status: "UU", path: "conflict.txt"},
+	}
+	assert.Equal(t, expectedEntries, entries)
+	assert.True(t, entries[4].isUnmerged())
+	assert.False(t, entries[0].isUnmerged())
+}
+
+func TestParseGitStatus_Empty(t *testing.T) {
+	entries, err := parseGitStatus([]byte{})
+
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestParseGitStatus_Invalid(t *testing.T) {
+	for _, gitStatus := range []string{"M\x00", "MM_PKGBUILD\x00", "R  new.patch\x00"} {
+		_, err := parseGitStatus([]byte(gitStatus))
+		assert.Error(t, err, gitStatus)
+	}
+}
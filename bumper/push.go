@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/bcyran/bumper/pack"
 )
@@ -20,7 +21,7 @@ type pushActionResult struct {
 }
 
 func (result *pushActionResult) String() string {
-	if result.Status == ActionSkippedStatus {
+	if result.Status == ActionSkippedStatus || result.Status == ActionPendingStatus {
 		return ""
 	}
 	if result.Status == ActionFailedStatus {
@@ -31,21 +32,59 @@ func (result *pushActionResult) String() string {
 
 type PushAction struct {
 	commandRunner CommandRunner
+	// gitRepos are git repositories of the packages, found in Prepare
+	gitRepos *GitRepos
+	// pendingRepos are shared repositories to push in Finish, with the paths of their packages
+	pendingRepos map[*gitRepo][]string
+	mtx          *sync.Mutex
 }
 
-func NewPushAction(commandRunner CommandRunner) *PushAction {
-	return &PushAction{commandRunner: commandRunner}
+func NewPushAction(commandRunner CommandRunner, gitRepos *GitRepos) *PushAction {
+	return &PushAction{commandRunner: commandRunner, gitRepos: gitRepos, pendingRepos: map[*gitRepo][]string{}, mtx: &sync.Mutex{}}
 }
 
-func (action *PushAction) Execute(pkg *pack.Package) ActionResult {
-	actionResult := &pushActionResult{}
+// Prepare finds git repositories of the packages, so the repositories shared by many packages are pushed once.
+func (action *PushAction) Prepare(pkgs []pack.Package) {
+	action.gitRepos.find(pkgs)
+}
 
+func (action *PushAction) Execute(pkg *pack.Package) ActionResult {
 	if !pkg.IsOutdated {
+		actionResult := &pushActionResult{}
 		actionResult.Status = ActionSkippedStatus
 		return actionResult
 	}
 
-	isOnMaster, err := action.isOnMaster(pkg)
+	if repo, isRepoKnown := action.gitRepos.get(pkg.Path); isRepoKnown && repo.isShared() {
+		action.mtx.Lock()
+		action.pendingRepos[repo.gitRepo] = append(action.pendingRepos[repo.gitRepo], pkg.Path)
+		action.mtx.Unlock()
+		actionResult := &pushActionResult{}
+		actionResult.Status = ActionPendingStatus
+		return actionResult
+	}
+
+	return action.push(pkg.Path)
+}
+
+// Finish pushes each of the shared repositories once, all their pending packages get the same result.
+func (action *PushAction) Finish() map[string]ActionResult {
+	results := map[string]ActionResult{}
+	for repo, pkgPaths := range action.pendingRepos {
+		result := action.push(repo.toplevel)
+		for _, pkgPath := range pkgPaths {
+			results[pkgPath] = result
+		}
+	}
+	action.pendingRepos = map[*gitRepo][]string{}
+	return results
+}
+
+// push pushes the repository in the given directory, if it's on master branch and ahead of origin.
+func (action *PushAction) push(cwd string) ActionResult {
+	actionResult := &pushActionResult{}
+
+	isOnMaster, err := action.isOnMaster(cwd)
 	if err != nil {
 		actionResult.Status = ActionFailedStatus
 		actionResult.Error = fmt.Errorf("%w: %w", ErrPushAction, err)
@@ -57,7 +96,7 @@ func (action *PushAction) Execute(pkg *pack.Package) ActionResult {
 		return actionResult
 	}
 
-	isBehindOrigin, err := action.isBehindOrigin(pkg)
+	isBehindOrigin, err := action.isBehindOrigin(cwd)
 	if err != nil {
 		actionResult.Status = ActionFailedStatus
 		actionResult.Error = fmt.Errorf("%w: %w", ErrPushAction, err)
@@ -68,7 +107,7 @@ func (action *PushAction) Execute(pkg *pack.Package) ActionResult {
 		return actionResult
 	}
 
-	if err := action.push(pkg); err != nil {
+	if _, err := action.commandRunner(cwd, "git", "push"); err != nil {
 		actionResult.Status = ActionFailedStatus
 		actionResult.Error = fmt.Errorf("%w: %w", ErrPushAction, err)
 		return actionResult
@@ -78,8 +117,8 @@ func (action *PushAction) Execute(pkg *pack.Package) ActionResult {
 	return actionResult
 }
 
-func (action *PushAction) isOnMaster(pkg *pack.Package) (bool, error) {
-	currentBranch, err := action.commandRunner(pkg.Path, "git", "branch", "--show-current")
+func (action *PushAction) isOnMaster(cwd string) (bool, error) {
+	currentBranch, err := action.commandRunner(cwd, "git", "branch", "--show-current")
 	if err != nil {
 		return false, err
 	}
@@ -91,8 +130,8 @@ func (action *PushAction) isOnMaster(pkg *pack.Package) (bool, error) {
 	return true, nil
 }
 
-func (action *PushAction) isBehindOrigin(pkg *pack.Package) (bool, error) {
-	gitRevList, err := action.commandRunner(pkg.Path, "git", "rev-list", "--left-right", "--count", diffTarget)
+func (action *PushAction) isBehindOrigin(cwd string) (bool, error) {
+	gitRevList, err := action.commandRunner(cwd, "git", "rev-list", "--left-right", "--count", diffTarget)
 	if err != nil {
 		return false, err
 	}
@@ -104,8 +143,3 @@ func (action *PushAction) isBehindOrigin(pkg *pack.Package) (bool, error) {
 
 	return true, nil
 }
-
-func (action *PushAction) push(pkg *pack.Package) error {
-	_, err := action.commandRunner(pkg.Path, "git", "push")
-	return err
-}
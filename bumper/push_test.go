@@ -24,7 +24,7 @@ func TestPushAction_Success(t *testing.T) {
 	fakeCommandRunner, commandRuns := testutils.MakeFakeCommandRunner(&commandRetvals)
 
 	// execute the action with our mocked command runner
-	action := NewPushAction(fakeCommandRunner)
+	action := NewPushAction(fakeCommandRunner, NewGitRepos(fakeCommandRunner))
 	result := action.Execute(pkg)
 
 	// result assertions
@@ -64,7 +64,7 @@ func TestPushAction_Skip(t *testing.T) {
 	fakeCommandRunner, _ := testutils.MakeFakeCommandRunner(&commandRetvals)
 
 	// execute the action with our mocked command runner
-	action := NewPushAction(fakeCommandRunner)
+	action := NewPushAction(fakeCommandRunner, NewGitRepos(fakeCommandRunner))
 	result := action.Execute(pkg)
 
 	assert.Equal(t, ActionSkippedStatus, result.GetStatus())
@@ -84,7 +84,7 @@ func TestPushAction_FailWrongBranch(t *testing.T) {
 	fakeCommandRunner, _ := testutils.MakeFakeCommandRunner(&commandRetvals)
 
 	// execute the action with our mocked command runner
-	action := NewPushAction(fakeCommandRunner)
+	action := NewPushAction(fakeCommandRunner, NewGitRepos(fakeCommandRunner))
 	result := action.Execute(pkg)
 
 	assert.Equal(t, ActionFailedStatus, result.GetStatus())
@@ -107,7 +107,7 @@ func TestPushAction_FailGitError(t *testing.T) {
 	fakeCommandRunner, _ := testutils.MakeFakeCommandRunner(&commandRetvals)
 
 	// execute the action with our mocked command runner
-	action := NewPushAction(fakeCommandRunner)
+	action := NewPushAction(fakeCommandRunner, NewGitRepos(fakeCommandRunner))
 	result := action.Execute(pkg)
 
 	assert.Equal(t, ActionFailedStatus, result.GetStatus())
@@ -115,3 +115,48 @@ func TestPushAction_FailGitError(t *testing.T) {
 	assert.ErrorContains(t, result.GetError(), expectedErr)
 	assert.ErrorContains(t, result.GetError(), "push action error")
 }
+
+func TestPushAction_SharedRepository(t *testing.T) {
+	pkgs := []pack.Package{
+		{Path: "/repo/foo", IsOutdated: true},
+		{Path: "/repo/bar", IsOutdated: true},
+		{Path: "/single", IsOutdated: true},
+	}
+	commandRetvals := []testutils.CommandRunnerRetval{
+		{Stdout: []byte("/repo\nfoo/\n"), Err: nil}, // git rev-parse
+		{Stdout: []byte("/repo\nbar/\n"), Err: nil}, // git rev-parse
+		{Stdout: []byte("/single\n\n"), Err: nil},   // git rev-parse
+		{Stdout: []byte("master\n"), Err: nil},      // checking branch of /single
+		{Stdout: []byte("1\t0\n"), Err: nil},        // checking if /single is up to date with origin
+		{Stdout: []byte{}, Err: nil},                // git push in /single
+		{Stdout: []byte("master\n"), Err: nil},      // checking branch of /repo
+		{Stdout: []byte("2\t0\n"), Err: nil},        // checking if /repo is up to date with origin
+		{Stdout: []byte{}, Err: nil},                // git push in /repo
+	}
+	fakeCommandRunner, commandRuns := testutils.MakeFakeCommandRunner(&commandRetvals)
+
+	action := NewPushAction(fakeCommandRunner, NewGitRepos(fakeCommandRunner))
+	action.Prepare(pkgs)
+
+	// packages in the shared repository are left pending
+	for _, pkg := range pkgs[:2] {
+		result := action.Execute(&pkg)
+		assert.Equal(t, ActionPendingStatus, result.GetStatus())
+		assert.Equal(t, "", result.String())
+	}
+	// other packages are pushed immediately
+	result := action.Execute(&pkgs[2])
+	assert.Equal(t, ActionSuccessStatus, result.GetStatus())
+
+	results := action.Finish()
+
+	assert.Len(t, results, 2)
+	for _, pkg := range pkgs[:2] {
+		assert.Equal(t, ActionSuccessStatus, results[pkg.Path].GetStatus())
+		assert.Equal(t, "pushed", results[pkg.Path].String())
+	}
+	// shared repository is pushed once, from its root
+	assert.Len(t, *commandRuns, 9)
+	expectedPushCommand := testutils.CommandRunnerParams{Cwd: "/repo", Command: "git", Args: []string{"push"}}
+	assert.Equal(t, expectedPushCommand, (*commandRuns)[8])
+}
@@ -0,0 +1,113 @@
+package bumper
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/bcyran/bumper/pack"
+	"go.uber.org/config"
+)
+
+var ErrRebuildAction = errors.New("rebuild action error")
+
+type rebuildActionResult struct {
+	BaseActionResult
+	fullVersion        string
+	rebuiltFullVersion string
+}
+
+func (result *rebuildActionResult) String() string {
+	if result.Status == ActionFailedStatus {
+		return "rebuild failed"
+	}
+	if result.Status == ActionSkippedStatus {
+		return result.fullVersion
+	}
+	return fmt.Sprintf("%s → %s", result.fullVersion, result.rebuiltFullVersion)
+}
+
+// RebuildAction increments pkgrel of the selected packages and regenerates their .SRCINFO.
+// It replaces check and bump actions, rebuilt packages go through the following actions like the bumped ones.
+type RebuildAction struct {
+	commandRunner CommandRunner
+	// against is the dependency the packages are rebuilt against, packages depending on it are selected
+	against string
+	// pkgbases are the packages selected explicitly
+	pkgbases []string
+	// keepFailed disables the rollback, so the files can be inspected
+	keepFailed bool
+	snapshots  packageSnapshots
+}
+
+func NewRebuildAction(commandRunner CommandRunner, against string, pkgbases []string, bumpConfig config.Value) *RebuildAction {
+	action := RebuildAction{commandRunner: commandRunner, against: against, pkgbases: pkgbases}
+	bumpConfig.Get("keepFailed").Populate(&action.keepFailed) //nolint:errcheck
+	return &action
+}
+
+func (action *RebuildAction) Execute(pkg *pack.Package) ActionResult {
+	actionResult := &rebuildActionResult{fullVersion: fmt.Sprintf("%s-%s", pkg.Pkgver, pkg.Pkgrel)}
+
+	if !action.isSelected(pkg) {
+		actionResult.Status = ActionSkippedStatus
+		return actionResult
+	}
+
+	rebuiltPkgrel, err := action.rebuild(pkg)
+	if err != nil {
+		actionResult.Status = ActionFailedStatus
+		actionResult.Error = fmt.Errorf("%w: %w", ErrRebuildAction, err)
+		return actionResult
+	}
+
+	pkg.IsRebuild = true
+	pkg.RebuildAgainst = action.against
+	pkg.IsOutdated = true
+
+	actionResult.Status = ActionSuccessStatus
+	actionResult.rebuiltFullVersion = fmt.Sprintf("%s-%s", pkg.Pkgver, rebuiltPkgrel)
+	return actionResult
+}
+
+// Rollback restores PKGBUILD and .SRCINFO from before the rebuild, unless keepFailed is set.
+func (action *RebuildAction) Rollback(pkg *pack.Package) error {
+	return action.snapshots.restore(pkg, action.keepFailed)
+}
+
+func (action *RebuildAction) isSelected(pkg *pack.Package) bool {
+	if slices.Contains(action.pkgbases, pkg.Pkgbase) {
+		return true
+	}
+	return action.against != "" && pkg.DependsOn(action.against)
+}
+
+// rebuild increments pkgrel in PKGBUILD and regenerates .SRCINFO, returns the new pkgrel.
+func (action *RebuildAction) rebuild(pkg *pack.Package) (string, error) {
+	rebuiltPkgrel, err := pack.IncrementPkgrel(pkg.Pkgrel)
+	if err != nil {
+		return "", err
+	}
+
+	if err := action.snapshots.take(pkg); err != nil {
+		return "", err
+	}
+
+	pkgbuild, err := os.ReadFile(pkg.PkgbuildPath())
+	if err != nil {
+		return "", fmt.Errorf("PKGBUILD reading error: %w", err)
+	}
+	updatedPkgbuild, err := setVariable(string(pkgbuild), "pkgrel", rebuiltPkgrel)
+	if err != nil {
+		return "", err
+	}
+	if err := writeFileAtomic(pkg.PkgbuildPath(), []byte(updatedPkgbuild), 0o644); err != nil {
+		return "", fmt.Errorf("PKGBUILD writing error: %w", err)
+	}
+
+	if err := writeSrcinfo(action.commandRunner, pkg); err != nil {
+		return "", err
+	}
+	return rebuiltPkgrel, nil
+}
@@ -0,0 +1,108 @@
+package bumper
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/bcyran/bumper/internal/testutils"
+	"github.com/bcyran/bumper/pack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeRebuildPackage(t *testing.T, pkgbase string, pkgrel string, depends []string) *pack.Package {
+	pkg := &pack.Package{
+		Path: t.TempDir(),
+		Srcinfo: &pack.Srcinfo{
+			Pkgbase:     pkgbase,
+			Depends:     depends,
+			FullVersion: &pack.FullVersion{Pkgver: pack.Version("1.0.0"), Pkgrel: pkgrel},
+		},
+	}
+	require.NoError(t, os.WriteFile(pkg.PkgbuildPath(), []byte(pkgbuildString("1.0.0", pkgrel)), 0o644))
+	require.NoError(t, os.WriteFile(pkg.SrcinfoPath(), []byte(srcinfoString("1.0.0")), 0o644))
+	return pkg
+}
+
+func TestRebuildAction_Success(t *testing.T) {
+	cases := map[string]string{"1": "2", "12": "13", "2.1": "3"}
+
+	for pkgrel, expectedPkgrel := range cases {
+		pkg := makeRebuildPackage(t, "foo", pkgrel, []string{"boost-libs", "python"})
+		commandRetvals := []testutils.CommandRunnerRetval{
+			{Stdout: []byte("rebuilt_srcinfo"), Err: nil}, // makepkg --printsrcinfo
+		}
+		fakeCommandRunner, commandRuns := testutils.MakeFakeCommandRunner(&commandRetvals)
+
+		action := NewRebuildAction(fakeCommandRunner, "python", nil, emptyBumpConfig)
+		result := action.Execute(pkg)
+
+		assert.Equal(t, ActionSuccessStatus, result.GetStatus(), pkgrel)
+		assert.Equal(t, "1.0.0-"+pkgrel+" → 1.0.0-"+expectedPkgrel, result.String())
+		assert.True(t, pkg.IsRebuild)
+		assert.True(t, pkg.IsOutdated)
+		assert.Equal(t, "python", pkg.RebuildAgainst)
+		pkgbuild, _ := os.ReadFile(pkg.PkgbuildPath())
+		assert.Equal(t, pkgbuildString("1.0.0", expectedPkgrel), string(pkgbuild))
+		srcinfo, _ := os.ReadFile(pkg.SrcinfoPath())
+		assert.Equal(t, "rebuilt_srcinfo", string(srcinfo))
+		expectedMakepkgCommand := testutils.CommandRunnerParams{Cwd: pkg.Path, Command: "makepkg", Args: []string{"--printsrcinfo"}}
+		assert.Equal(t, []testutils.CommandRunnerParams{expectedMakepkgCommand}, *commandRuns)
+	}
+}
+
+func TestRebuildAction_Selection(t *testing.T) {
+	fakeCommandRunner, _ := testutils.MakeFakeCommandRunner(&[]testutils.CommandRunnerRetval{
+		{Stdout: []byte("srcinfo"), Err: nil},
+		{Stdout: []byte("srcinfo"), Err: nil},
+	})
+	action := NewRebuildAction(fakeCommandRunner, "icu", []string{"bar"}, emptyBumpConfig)
+	cases := map[*pack.Package]ActionStatus{
+		makeRebuildPackage(t, "foo", "1", []string{"icu"}):    ActionSuccessStatus,
+		makeRebuildPackage(t, "bar", "1", []string{}):         ActionSuccessStatus,
+		makeRebuildPackage(t, "baz", "1", []string{"python"}): ActionSkippedStatus,
+	}
+
+	for pkg, expectedStatus := range cases {
+		result := action.Execute(pkg)
+
+		assert.Equal(t, expectedStatus, result.GetStatus(), pkg.Pkgbase)
+		assert.Equal(t, expectedStatus == ActionSuccessStatus, pkg.IsOutdated, pkg.Pkgbase)
+		if expectedStatus == ActionSkippedStatus {
+			assert.Equal(t, "1.0.0-1", result.String())
+		}
+	}
+}
+
+func TestRebuildAction_FailAndRollback(t *testing.T) {
+	pkg := makeRebuildPackage(t, "foo", "1", []string{"python"})
+	commandRetvals := []testutils.CommandRunnerRetval{
+		{Stdout: []byte{}, Err: errors.New("makepkg failed")},
+	}
+	fakeCommandRunner, _ := testutils.MakeFakeCommandRunner(&commandRetvals)
+	action := NewRebuildAction(fakeCommandRunner, "python", nil, emptyBumpConfig)
+
+	result := action.Execute(pkg)
+
+	assert.Equal(t, ActionFailedStatus, result.GetStatus())
+	assert.ErrorIs(t, result.GetError(), ErrRebuildAction)
+	assert.Equal(t, "rebuild failed", result.String())
+	assert.False(t, pkg.IsOutdated)
+
+	assert.NoError(t, action.Rollback(pkg))
+	pkgbuild, _ := os.ReadFile(pkg.PkgbuildPath())
+	assert.Equal(t, pkgbuildString("1.0.0", "1"), string(pkgbuild))
+}
+
+func TestRebuildAction_InvalidPkgrel(t *testing.T) {
+	pkg := makeRebuildPackage(t, "foo", "whatever", []string{"python"})
+	fakeCommandRunner, commandRuns := testutils.MakeFakeCommandRunner(&[]testutils.CommandRunnerRetval{})
+	action := NewRebuildAction(fakeCommandRunner, "python", nil, emptyBumpConfig)
+
+	result := action.Execute(pkg)
+
+	assert.Equal(t, ActionFailedStatus, result.GetStatus())
+	assert.ErrorContains(t, result.GetError(), "invalid pkgrel 'whatever'")
+	assert.Len(t, *commandRuns, 0)
+}
@@ -0,0 +1,86 @@
+package bumper
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/bcyran/bumper/pack"
+)
+
+// gitRepo is a git repository holding one or more of the packages.
+type gitRepo struct {
+	toplevel string
+	// packagesCount is the number of the packages in the repository
+	packagesCount int
+	// mtx serializes git operations in the repository, concurrent ones collide on the index lock
+	mtx *sync.Mutex
+}
+
+// isShared checks whether the repository holds more than one package.
+func (repo *gitRepo) isShared() bool {
+	return repo.packagesCount > 1
+}
+
+// packageRepo is the git repository of a package.
+type packageRepo struct {
+	*gitRepo
+	// prefix is the package path relative to the repository root, with trailing slash, empty if it's the root
+	prefix string
+}
+
+// packagePath converts path relative to the repository root, as reported by git status,
+// to the path relative to the package. Returns false if the path is outside of the package.
+func (repo packageRepo) packagePath(path string) (string, bool) {
+	return strings.CutPrefix(path, repo.prefix)
+}
+
+// findGitRepos finds git repositories of the packages, keyed by the package paths.
+// Packages which are not in a git repository are omitted, git operations will report the error later.
+func findGitRepos(commandRunner CommandRunner, pkgs []pack.Package) map[string]packageRepo {
+	reposByToplevel := map[string]*gitRepo{}
+	pkgRepos := map[string]packageRepo{}
+	for _, pkg := range pkgs {
+		revParse, err := commandRunner(pkg.Path, "git", "rev-parse", "--show-toplevel", "--show-prefix")
+		if err != nil {
+			DebugLogger.Printf("Git repository of %s not found: %v", pkg.Path, err)
+			continue
+		}
+		// prefix is an empty line if the package is in the repository root
+		toplevel, prefix, _ := strings.Cut(strings.TrimRight(string(revParse), "\n"), "\n")
+
+		repo, isKnown := reposByToplevel[toplevel]
+		if !isKnown {
+			repo = &gitRepo{toplevel: toplevel, mtx: &sync.Mutex{}}
+			reposByToplevel[toplevel] = repo
+		}
+		repo.packagesCount++
+		pkgRepos[pkg.Path] = packageRepo{gitRepo: repo, prefix: prefix}
+	}
+	return pkgRepos
+}
+
+// GitRepos holds git repositories of the packages, shared by the actions which need them.
+// The repositories are found once, so all the actions use the same mutex for a repository.
+type GitRepos struct {
+	commandRunner CommandRunner
+	once          sync.Once
+	// repos are keyed by the package paths
+	repos map[string]packageRepo
+}
+
+func NewGitRepos(commandRunner CommandRunner) *GitRepos {
+	return &GitRepos{commandRunner: commandRunner}
+}
+
+// find finds git repositories of the packages. Only the first call does the work, subsequent ones are no-op.
+func (gitRepos *GitRepos) find(pkgs []pack.Package) {
+	gitRepos.once.Do(func() {
+		gitRepos.repos = findGitRepos(gitRepos.commandRunner, pkgs)
+	})
+}
+
+// get returns the repository of the package with the given path, false if it's unknown.
+func (gitRepos *GitRepos) get(pkgPath string) (packageRepo, bool) {
+	repo, isKnown := gitRepos.repos[pkgPath]
+	return repo, isKnown
+}
@@ -0,0 +1,68 @@
+package bumper
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bcyran/bumper/internal/testutils"
+	"github.com/bcyran/bumper/pack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindGitRepos(t *testing.T) {
+	pkgs := []pack.Package{{Path: "/repo/foo"}, {Path: "/repo/bar"}, {Path: "/single"}, {Path: "/nothing"}}
+	commandRetvals := []testutils.CommandRunnerRetval{
+		{Stdout: []byte("/repo\nfoo/\n"), Err: nil},
+		{Stdout: []byte("/repo\nbar/\n"), Err: nil},
+		{Stdout: []byte("/single\n\n"), Err: nil},
+		{Stdout: []byte{}, Err: errors.New("not a git repository")},
+	}
+	fakeCommandRunner, commandRuns := testutils.MakeFakeCommandRunner(&commandRetvals)
+
+	repos := findGitRepos(fakeCommandRunner, pkgs)
+
+	assert.Equal(t, testutils.CommandRunnerParams{Cwd: "/repo/foo", Command: "git", Args: []string{"rev-parse", "--show-toplevel", "--show-prefix"}}, (*commandRuns)[0])
+	require.Len(t, repos, 3)
+	assert.Same(t, repos["/repo/foo"].gitRepo, repos["/repo/bar"].gitRepo)
+	assert.Equal(t, "/repo", repos["/repo/foo"].toplevel)
+	assert.True(t, repos["/repo/foo"].isShared())
+	assert.Equal(t, "foo/", repos["/repo/foo"].prefix)
+	assert.Equal(t, "bar/", repos["/repo/bar"].prefix)
+	assert.False(t, repos["/single"].isShared())
+	assert.Equal(t, "", repos["/single"].prefix)
+}
+
+func TestPackageRepo_PackagePath(t *testing.T) {
+	repo := packageRepo{gitRepo: &gitRepo{toplevel: "/repo"}, prefix: "foo/"}
+
+	path, isInPackage := repo.packagePath("foo/PKGBUILD")
+	assert.True(t, isInPackage)
+	assert.Equal(t, "PKGBUILD", path)
+
+	_, isInPackage = repo.packagePath("bar/PKGBUILD")
+	assert.False(t, isInPackage)
+}
+
+func TestGitRepos_SharedByActions(t *testing.T) {
+	pkgs := []pack.Package{{Path: "/repo/foo"}, {Path: "/repo/bar"}}
+	commandRetvals := []testutils.CommandRunnerRetval{
+		{Stdout: []byte("/repo\nfoo/\n"), Err: nil},
+		{Stdout: []byte("/repo\nbar/\n"), Err: nil},
+	}
+	fakeCommandRunner, commandRuns := testutils.MakeFakeCommandRunner(&commandRetvals)
+	gitRepos := NewGitRepos(fakeCommandRunner)
+	commitAction := NewCommitAction(fakeCommandRunner, nil, gitRepos, emptyCommitConfig)
+	pushAction := NewPushAction(fakeCommandRunner, gitRepos)
+
+	commitAction.Prepare(pkgs)
+	pushAction.Prepare(pkgs)
+
+	// repositories are found only once
+	assert.Len(t, *commandRuns, 2)
+	commitRepo, isCommitRepoKnown := commitAction.gitRepos.get("/repo/foo")
+	pushRepo, isPushRepoKnown := pushAction.gitRepos.get("/repo/foo")
+	require.True(t, isCommitRepoKnown)
+	require.True(t, isPushRepoKnown)
+	assert.Same(t, commitRepo.mtx, pushRepo.mtx)
+}
@@ -1,6 +1,7 @@
 package bumper
 
 import (
+	"errors"
 	"sync"
 
 	"github.com/bcyran/bumper/pack"
@@ -14,35 +15,89 @@ type (
 // Run runs actions for the packages, blocks until all results are handled.
 // For each result, and on finished processing, appropriate handlers are called.
 // Actions and handlers for a single package are run sequentially, but the packages are handled concurrently.
+// Before that, all the PreparingActions are prepared with all the packages.
+// Packages left pending by BatchingActions are finished after all the other packages.
 func Run(pkgs []pack.Package, actions []Action, resultHandler ResultHandler, finishedHandler FinishedHandler) {
+	for _, action := range actions {
+		if preparingAction, isPreparing := action.(PreparingAction); isPreparing {
+			preparingAction.Prepare(pkgs)
+		}
+	}
+
+	isPending := make([]bool, len(pkgs))
 	pkgWorkersWg := sync.WaitGroup{}
 	for i := range pkgs {
 		pkgWorkersWg.Add(1)
 		go func(pkgIndex int) {
 			packageResultHandler := func(result ActionResult) { resultHandler(pkgIndex, result) }
 			packageFinishedHandler := func() { finishedHandler(pkgIndex) }
-			packageWorker(&pkgs[pkgIndex], actions, packageResultHandler, packageFinishedHandler)
+			isPending[pkgIndex] = packageWorker(&pkgs[pkgIndex], actions, packageResultHandler, packageFinishedHandler)
 			pkgWorkersWg.Done()
 		}(i)
 	}
 	pkgWorkersWg.Wait()
+
+	finishPending(pkgs, actions, isPending, resultHandler, finishedHandler)
 }
 
 // packageWorker runs RunPackageActions, listens for results, and runs the handlers sequentially.
-func packageWorker(pkg *pack.Package, actions []Action, resultHandler func(ActionResult), finishedHandler func()) {
+// If the package is left pending, finished handler is not run and true is returned.
+func packageWorker(pkg *pack.Package, actions []Action, resultHandler func(ActionResult), finishedHandler func()) bool {
 	resultChan := make(chan ActionResult)
 	go runPackageActions(pkg, actions, resultChan)
+	var lastResult ActionResult
 	for result := range resultChan {
 		resultHandler(result)
+		lastResult = result
+	}
+	if lastResult != nil && lastResult.GetStatus() == ActionPendingStatus {
+		return true
 	}
 	finishedHandler()
+	return false
+}
+
+// finishPending finishes the BatchingActions, handles the final results of the pending packages and finishes them.
+func finishPending(pkgs []pack.Package, actions []Action, isPending []bool, resultHandler ResultHandler, finishedHandler FinishedHandler) {
+	for _, action := range actions {
+		batchingAction, isBatching := action.(BatchingAction)
+		if !isBatching {
+			continue
+		}
+		results := batchingAction.Finish()
+		for i := range pkgs {
+			if result, hasResult := results[pkgs[i].Path]; isPending[i] && hasResult {
+				resultHandler(i, result)
+			}
+		}
+	}
+
+	for i := range pkgs {
+		if isPending[i] {
+			finishedHandler(i)
+		}
+	}
 }
 
 // runPackageActions runs actions for a package sequentially and writes the results to the channel.
+// If an action fails, changes made by the executed actions are rolled back before its result is written.
 func runPackageActions(pkg *pack.Package, actions []Action, resultChan chan ActionResult) {
+	pendingRollbacks := []RollbackAction{}
 	for _, action := range actions {
 		actionResult := action.Execute(pkg)
 
+		if rollbackAction, isRollback := action.(RollbackAction); isRollback {
+			pendingRollbacks = append(pendingRollbacks, rollbackAction)
+		}
+		if _, isCommitting := action.(committingAction); isCommitting && actionResult.GetStatus() == ActionSuccessStatus {
+			pendingRollbacks = []RollbackAction{}
+		}
+		if actionResult.GetStatus() == ActionFailedStatus {
+			if err := rollback(pkg, pendingRollbacks); err != nil {
+				actionResult = &rollbackFailedResult{ActionResult: actionResult, rollbackErr: err}
+			}
+		}
+
 		resultChan <- actionResult
 
 		if actionResult.GetStatus() != ActionSuccessStatus {
@@ -52,3 +107,14 @@ func runPackageActions(pkg *pack.Package, actions []Action, resultChan chan Acti
 
 	close(resultChan)
 }
+
+// rollback rolls back the actions in reverse order.
+func rollback(pkg *pack.Package, rollbackActions []RollbackAction) error {
+	rollbackErrs := []error{}
+	for i := len(rollbackActions) - 1; i >= 0; i-- {
+		if err := rollbackActions[i].Rollback(pkg); err != nil {
+			rollbackErrs = append(rollbackErrs, err)
+		}
+	}
+	return errors.Join(rollbackErrs...)
+}
@@ -1,11 +1,18 @@
 package bumper
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"strings"
+	"sync"
 	"testing"
 
+	"github.com/bcyran/bumper/internal/testutils"
 	"github.com/bcyran/bumper/pack"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/config"
 )
 
 type testActionResult struct {
@@ -124,3 +131,176 @@ func TestRun_Skip(t *testing.T) {
 	assert.ElementsMatch(t, actualResults, expectedResults)
 	assert.ElementsMatch(t, actualFinished, expectedFinished)
 }
+
+type testPreparingAction struct {
+	testAction
+	preparedWith []string
+}
+
+func (action *testPreparingAction) Prepare(pkgs []pack.Package) {
+	for _, pkg := range pkgs {
+		action.preparedWith = append(action.preparedWith, pkg.Pkgbase)
+	}
+}
+
+func TestRun_Prepare(t *testing.T) {
+	packages := []pack.Package{
+		{Srcinfo: &pack.Srcinfo{Pkgbase: "pkgA"}},
+		{Srcinfo: &pack.Srcinfo{Pkgbase: "pkgB"}},
+	}
+	preparingAction := &testPreparingAction{testAction: testAction{retStatus: ActionSuccessStatus, retString: "prepared"}}
+	actions := []Action{
+		newTestAction(ActionSuccessStatus, "first result"),
+		preparingAction,
+	}
+
+	Run(packages, actions, func(int, ActionResult) {}, func(int) {})
+
+	// prepared once, with all the packages
+	assert.Equal(t, []string{"pkgA", "pkgB"}, preparingAction.preparedWith)
+}
+
+type testRollbackAction struct {
+	testAction
+	rollbackErr  error
+	rolledBack   *[]string
+	rollbackName string
+}
+
+func (action *testRollbackAction) Rollback(_pkg *pack.Package) error {
+	*action.rolledBack = append(*action.rolledBack, action.rollbackName)
+	return action.rollbackErr
+}
+
+type testCommittingAction struct {
+	testAction
+}
+
+func (action *testCommittingAction) commitsChanges() {}
+
+func runSinglePackage(actions []Action) []ActionResult {
+	packages := []pack.Package{{Srcinfo: &pack.Srcinfo{Pkgbase: "pkgA"}}}
+	results := []ActionResult{}
+	Run(packages, actions, func(_ int, result ActionResult) { results = append(results, result) }, func(int) {})
+	return results
+}
+
+func TestRun_Rollback(t *testing.T) {
+	rolledBack := []string{}
+	actions := []Action{
+		&testRollbackAction{testAction: testAction{retStatus: ActionSuccessStatus}, rolledBack: &rolledBack, rollbackName: "first"},
+		&testRollbackAction{testAction: testAction{retStatus: ActionSuccessStatus}, rolledBack: &rolledBack, rollbackName: "second"},
+		newTestAction(ActionFailedStatus, "failed"),
+	}
+
+	results := runSinglePackage(actions)
+
+	assert.Len(t, results, 3)
+	assert.Equal(t, []string{"second", "first"}, rolledBack)
+	assert.NoError(t, results[2].GetError())
+}
+
+func TestRun_RollbackFailed(t *testing.T) {
+	rolledBack := []string{}
+	actions := []Action{
+		&testRollbackAction{testAction: testAction{retStatus: ActionFailedStatus, retString: "failed"}, rolledBack: &rolledBack, rollbackName: "first", rollbackErr: errors.New("disk full")},
+	}
+
+	results := runSinglePackage(actions)
+
+	assert.Equal(t, []string{"first"}, rolledBack)
+	assert.Equal(t, ActionFailedStatus, results[0].GetStatus())
+	assert.Equal(t, "pkgA: failed", results[0].String())
+	assert.ErrorContains(t, results[0].GetError(), "rollback failed: disk full")
+}
+
+func TestRun_NoRollbackAfterCommit(t *testing.T) {
+	rolledBack := []string{}
+	actions := []Action{
+		&testRollbackAction{testAction: testAction{retStatus: ActionSuccessStatus}, rolledBack: &rolledBack, rollbackName: "first"},
+		&testCommittingAction{testAction: testAction{retStatus: ActionSuccessStatus}},
+		newTestAction(ActionFailedStatus, "push failed"),
+	}
+
+	results := runSinglePackage(actions)
+
+	assert.Len(t, results, 3)
+	assert.Empty(t, rolledBack)
+}
+
+type testBatchingAction struct{}
+
+func (action *testBatchingAction) Execute(pkg *pack.Package) ActionResult {
+	if pkg.Pkgbase == "pkgA" {
+		return newTestActionResult(ActionSuccessStatus, "done now")
+	}
+	return newTestActionResult(ActionPendingStatus, "")
+}
+
+func (action *testBatchingAction) Finish() map[string]ActionResult {
+	return map[string]ActionResult{"/pkgB": newTestActionResult(ActionSuccessStatus, "done later")}
+}
+
+func TestRun_Batching(t *testing.T) {
+	packages := []pack.Package{
+		{Path: "/pkgA", Srcinfo: &pack.Srcinfo{Pkgbase: "pkgA"}},
+		{Path: "/pkgB", Srcinfo: &pack.Srcinfo{Pkgbase: "pkgB"}},
+	}
+	actions := []Action{&testBatchingAction{}}
+
+	// events of both packages, in order
+	events := []string{}
+	mtx := sync.Mutex{}
+	handleResult := func(pkgIndex int, result ActionResult) {
+		mtx.Lock()
+		events = append(events, fmt.Sprintf("%s: result '%s'", packages[pkgIndex].Pkgbase, result.String()))
+		mtx.Unlock()
+	}
+	handleFinished := func(pkgIndex int) {
+		mtx.Lock()
+		events = append(events, fmt.Sprintf("%s: finished", packages[pkgIndex].Pkgbase))
+		mtx.Unlock()
+	}
+	Run(packages, actions, handleResult, handleFinished)
+
+	assert.Len(t, events, 5)
+	// pending package gets the final result and is finished after all the others
+	assert.Equal(t, []string{"pkgB: result 'done later'", "pkgB: finished"}, events[3:])
+	assert.ElementsMatch(t, []string{"pkgA: result 'done now'", "pkgA: finished", "pkgB: result ''"}, events[:3])
+}
+
+func TestRun_CommitFailed(t *testing.T) {
+	pkg := makeOutdatedPackage(t.TempDir(), "1.0.0", "1", "2.0.0")
+	pkgbuildBefore := pkgbuildString("1.0.0", "1")
+	require.NoError(t, os.WriteFile(pkg.PkgbuildPath(), []byte(pkgbuildBefore), 0o644))
+	require.NoError(t, os.WriteFile(pkg.SrcinfoPath(), []byte(srcinfoString("1.0.0")), 0o644))
+	commandRetvals := []testutils.CommandRunnerRetval{
+		{Stdout: []byte(pkg.Path + "\n\n"), Err: nil},                // git rev-parse
+		{Stdout: []byte{}, Err: nil},                                 // updpkgsums
+		{Stdout: []byte(srcinfoString("2.0.0")), Err: nil},           // makepkg --printsrcinfo
+		{Stdout: []byte(" M .SRCINFO\x00 M PKGBUILD\x00"), Err: nil}, // git status
+		{Stdout: []byte{}, Err: nil},                                 // git add
+		{Stdout: []byte{}, Err: errors.New("hook failed")},           // git commit
+		{Stdout: []byte{}, Err: nil},                                 // git reset
+	}
+	fakeCommandRunner, commandRuns := testutils.MakeFakeCommandRunner(&commandRetvals)
+	emptyConfig, _ := config.NewYAML(config.Source(strings.NewReader("{}")))
+	actions := []Action{
+		NewBumpAction(fakeCommandRunner, emptyConfig.Get("bump")),
+		NewCommitAction(fakeCommandRunner, nil, NewGitRepos(fakeCommandRunner), emptyConfig.Get("commit")),
+	}
+
+	results := []ActionResult{}
+	Run([]pack.Package{*pkg}, actions, func(_ int, result ActionResult) { results = append(results, result) }, func(int) {})
+
+	require.Len(t, results, 2)
+	assert.Equal(t, ActionFailedStatus, results[1].GetStatus())
+	assert.ErrorContains(t, results[1].GetError(), "hook failed")
+	// the bump is reverted both in the working tree and in the index
+	pkgbuild, _ := os.ReadFile(pkg.PkgbuildPath())
+	assert.Equal(t, pkgbuildBefore, string(pkgbuild))
+	expectedResetCommand := testutils.CommandRunnerParams{
+		Cwd: pkg.Path, Command: "git", Args: []string{"reset", "--quiet", "--", "PKGBUILD", ".SRCINFO"},
+	}
+	assert.Equal(t, expectedResetCommand, (*commandRuns)[6])
+}
@@ -0,0 +1,110 @@
+package bumper
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/bcyran/bumper/pack"
+)
+
+// variableValues holds the values available in bump variable templates.
+type variableValues struct {
+	Version string
+	// Raw is the upstream version string before normalisation, e.g. 1.2.3-rc1, or Version if it's unknown
+	Raw string
+	// Parts are the dot separated version components, Major, Minor and Patch are the first three of them
+	Parts  []string
+	Major  string
+	Minor  string
+	Patch  string
+	Tag    string
+	Commit string
+}
+
+func makeVariableValues(pkg *pack.Package) variableValues {
+	version := pkg.UpstreamVersion.GetVersionStr()
+	parts := strings.Split(version, ".")
+	part := func(index int) string {
+		if index < len(parts) {
+			return parts[index]
+		}
+		return ""
+	}
+	raw := pkg.UpstreamRawVersion
+	if raw == "" {
+		raw = version
+	}
+	return variableValues{
+		Version: version,
+		Raw:     raw,
+		Parts:   parts,
+		Major:   part(0),
+		Minor:   part(1),
+		Patch:   part(2),
+		Tag:     pkg.UpstreamRevision.Tag,
+		Commit:  pkg.UpstreamRevision.Commit,
+	}
+}
+
+// variableFuncs are the functions available in bump variable templates, in addition to the builtin ones.
+var variableFuncs = template.FuncMap{
+	"trimPrefix": strings.TrimPrefix,
+}
+
+// renderVariables renders templates of the PKGBUILD variables with the upstream version values of the package.
+func renderVariables(templates map[string]string, pkg *pack.Package) (map[string]string, error) {
+	values := makeVariableValues(pkg)
+	variables := map[string]string{}
+	for name, templateStr := range templates {
+		variableTemplate, err := template.New(name).Funcs(variableFuncs).Option("missingkey=error").Parse(templateStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template of variable %s: %w", name, err)
+		}
+		valueBuilder := strings.Builder{}
+		if err := variableTemplate.Execute(&valueBuilder, values); err != nil {
+			return nil, fmt.Errorf("invalid template of variable %s: %w", name, err)
+		}
+		if valueBuilder.Len() == 0 {
+			return nil, fmt.Errorf("variable %s is empty, upstream tag, commit or version component may be unknown", name)
+		}
+		variables[name] = valueBuilder.String()
+	}
+	return variables, nil
+}
+
+// setVariables sets values of the given top-level variables in the PKGBUILD contents.
+func setVariables(pkgbuild string, variables map[string]string) (string, error) {
+	names := make([]string, 0, len(variables))
+	for name := range variables {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		var err error
+		if pkgbuild, err = setVariable(pkgbuild, name, variables[name]); err != nil {
+			return "", err
+		}
+	}
+	return pkgbuild, nil
+}
+
+// setVariable sets value of the top-level variable in the PKGBUILD contents, keeping its quoting style.
+func setVariable(pkgbuild string, name string, value string) (string, error) {
+	variablePattern := regexp.MustCompile(`(?m)^(` + regexp.QuoteMeta(name) + `=)('[^'\n]*'|"[^"\n]*"|[^\s'"#;]*)`)
+	if !variablePattern.MatchString(pkgbuild) {
+		return "", fmt.Errorf("variable %s not found in PKGBUILD", name)
+	}
+
+	return variablePattern.ReplaceAllStringFunc(pkgbuild, func(assignment string) string {
+		match := variablePattern.FindStringSubmatch(assignment)
+		quote := ""
+		if oldValue := match[2]; oldValue != "" && (oldValue[0] == '\'' || oldValue[0] == '"') {
+			quote = oldValue[:1]
+		}
+		return match[1] + quote + value + quote
+	}), nil
+}
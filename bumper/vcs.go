@@ -0,0 +1,146 @@
+package bumper
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bcyran/bumper/pack"
+	"github.com/bcyran/bumper/upstream"
+	"go.uber.org/config"
+)
+
+const defaultMinBehind = 1
+
+type vcsChecker func(source string, revision upstream.VCSRevision) (upstream.VCSStatus, error)
+
+// VCSCheck checks how far VCS packages are behind their upstream repos and optionally refreshes their pkgver.
+type VCSCheck struct {
+	vcsChecker    vcsChecker
+	commandRunner CommandRunner
+	// refresh enables running pkgver() to get the new version of outdated packages
+	refresh bool
+	// minBehind is the number of commits the package has to be behind to be considered outdated
+	minBehind int
+}
+
+func NewVCSCheck(vcsChecker vcsChecker, commandRunner CommandRunner, vcsConfig config.Value) *VCSCheck {
+	vcsCheck := VCSCheck{vcsChecker: vcsChecker, commandRunner: commandRunner, minBehind: defaultMinBehind}
+	vcsConfig.Get("refresh").Populate(&vcsCheck.refresh)     //nolint:errcheck
+	vcsConfig.Get("minBehind").Populate(&vcsCheck.minBehind) //nolint:errcheck
+	return &vcsCheck
+}
+
+// execute checks the VCS package and marks it as outdated if it's behind the upstream.
+// Upstream version is known only if pkgver is refreshed.
+func (vcsCheck *VCSCheck) execute(pkg *pack.Package, actionResult *checkActionResult) error {
+	actionResult.isVCS = true
+	actionResult.currentVersion = pkg.Pkgver
+
+	revision, isValid := upstream.ParseVCSPkgver(pkg.Pkgver.GetVersionStr())
+	if !isValid {
+		return fmt.Errorf("pkgver '%s' doesn't contain commit hash", pkg.Pkgver)
+	}
+	source, isFound := getVCSSource(pkg)
+	if !isFound {
+		return fmt.Errorf("no git source found")
+	}
+
+	status, err := vcsCheck.vcsChecker(source, revision)
+	if err != nil {
+		return err
+	}
+	actionResult.vcsBehind = status.Behind
+
+	isBehind := status.Behind == upstream.VCSBehindUnknown || status.Behind >= vcsCheck.minBehind
+	if !isBehind || !vcsCheck.refresh {
+		return nil
+	}
+
+	refreshedPkgver, err := vcsCheck.refreshPkgver(pkg)
+	if err != nil {
+		return fmt.Errorf("pkgver refresh failed: %w", err)
+	}
+	upstreamVersion := upstream.Version(refreshedPkgver)
+	pkg.UpstreamVersion = upstreamVersion
+	pkg.IsOutdated = pack.VersionCmp(upstreamVersion, pkg.Pkgver) == 1
+	actionResult.upstreamVersion = upstreamVersion
+
+	return nil
+}
+
+// refreshPkgver runs pkgver() on a temporary copy of the package and returns the resulting pkgver.
+func (vcsCheck *VCSCheck) refreshPkgver(pkg *pack.Package) (pack.Version, error) {
+	tmpDir, err := os.MkdirTemp("", "bumper-vcs-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	buildDir := filepath.Join(tmpDir, "package")
+	if err := copyPackageFiles(pkg.Path, buildDir); err != nil {
+		return "", err
+	}
+
+	_, err = vcsCheck.commandRunner(
+		buildDir, "env", "SRCDEST="+filepath.Join(tmpDir, "src"), "BUILDDIR="+filepath.Join(tmpDir, "build"),
+		"makepkg", "--nobuild", "--nodeps", "--skipinteg",
+	)
+	if err != nil {
+		return "", err
+	}
+	srcinfo, err := vcsCheck.commandRunner(buildDir, "makepkg", "--printsrcinfo")
+	if err != nil {
+		return "", err
+	}
+
+	srcinfoPath := filepath.Join(buildDir, ".SRCINFO")
+	if err := os.WriteFile(srcinfoPath, srcinfo, 0o644); err != nil {
+		return "", fmt.Errorf(".SRCINFO writing error: %w", err)
+	}
+	refreshedSrcinfo, err := pack.ParseSrcinfo(srcinfoPath)
+	if err != nil {
+		return "", err
+	}
+	return refreshedSrcinfo.Pkgver, nil
+}
+
+// copyPackageFiles copies regular files from the package directory to the destination directory.
+// Subdirectories, e.g. previously downloaded sources, are skipped.
+func copyPackageFiles(pkgPath string, destPath string) error {
+	if err := os.MkdirAll(destPath, 0o755); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(pkgPath)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(filepath.Join(pkgPath, entry.Name()))
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(destPath, entry.Name()), content, info.Mode().Perm()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getVCSSource returns the first git source of the package.
+func getVCSSource(pkg *pack.Package) (string, bool) {
+	for _, sourceURL := range getPackageUrls(pkg)[1:] {
+		if strings.HasPrefix(sourceURL, "git+") || strings.HasPrefix(sourceURL, "git://") {
+			return sourceURL, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,153 @@
+package bumper
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bcyran/bumper/internal/testutils"
+	"github.com/bcyran/bumper/pack"
+	"github.com/bcyran/bumper/upstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/config"
+)
+
+const refreshedSrcinfo = `
+pkgbase = foo-git
+	pkgname = foo-git
+	url = https://foo.example.com
+	pkgver = 1.2.r8.g89abcde
+	pkgrel = 1
+`
+
+func vcsConfig(t *testing.T, yaml string) config.Value {
+	configProvider, err := config.NewYAML(config.Source(strings.NewReader(yaml)))
+	require.NoError(t, err)
+	return configProvider.Get("vcs")
+}
+
+func vcsPackage(path string) pack.Package {
+	return pack.Package{
+		Srcinfo: &pack.Srcinfo{
+			Pkgbase:     "foo-git",
+			URL:         "https://foo.example.com",
+			Source:      []string{"foo.patch", "foo::git+https://github.com/foo/foo.git#branch=main"},
+			FullVersion: &pack.FullVersion{Pkgver: pack.Version("1.2.r3.g0123456"), Pkgrel: "1"},
+		},
+		Path:  path,
+		IsVCS: true,
+	}
+}
+
+func fakeVCSChecker(status upstream.VCSStatus, err error, calls *[]string) vcsChecker {
+	return func(source string, revision upstream.VCSRevision) (upstream.VCSStatus, error) {
+		*calls = append(*calls, source+" "+revision.Hash)
+		return status, err
+	}
+}
+
+func TestCheckAction_VCSUpToDate(t *testing.T) {
+	calls := []string{}
+	vcsCheck := NewVCSCheck(fakeVCSChecker(upstream.VCSStatus{Head: "0123456789", Behind: 0}, nil, &calls), nil, vcsConfig(t, "{}"))
+	action := NewCheckAction(nil, nil, vcsCheck, emptyCheckConfig)
+	pkg := vcsPackage("")
+
+	result := action.Execute(&pkg)
+
+	assert.Equal(t, ActionSuccessStatus, result.GetStatus())
+	assert.Equal(t, "1.2.r3.g0123456", result.String())
+	assert.Equal(t, []string{"git+https://github.com/foo/foo.git#branch=main 0123456"}, calls)
+	assert.False(t, pkg.IsOutdated)
+}
+
+func TestCheckAction_VCSBehind(t *testing.T) {
+	cases := map[int]string{
+		5:                         "1.2.r3.g0123456 (5 commits behind)",
+		upstream.VCSBehindUnknown: "1.2.r3.g0123456 (behind)",
+	}
+
+	for behind, expectedString := range cases {
+		calls := []string{}
+		vcsCheck := NewVCSCheck(fakeVCSChecker(upstream.VCSStatus{Head: "89abcde", Behind: behind}, nil, &calls), nil, vcsConfig(t, "{}"))
+		action := NewCheckAction(nil, nil, vcsCheck, emptyCheckConfig)
+		pkg := vcsPackage("")
+
+		result := action.Execute(&pkg)
+
+		assert.Equal(t, ActionSuccessStatus, result.GetStatus())
+		assert.Equal(t, expectedString, result.String())
+		// Without refresh the new pkgver is unknown, so the package can't be bumped
+		assert.False(t, pkg.IsOutdated)
+	}
+}
+
+func TestCheckAction_VCSRefresh(t *testing.T) {
+	pkgPath := filepath.Join(t.TempDir(), "foo-git")
+	require.NoError(t, testutils.CreatePackage(pkgPath, []byte("pkgver=1.2.r3.g0123456\n"), []byte{}))
+	require.NoError(t, os.Mkdir(filepath.Join(pkgPath, "src"), 0o755))
+	commandRetvals := []testutils.CommandRunnerRetval{
+		{Stdout: []byte{}, Err: nil},                 // makepkg --nobuild
+		{Stdout: []byte(refreshedSrcinfo), Err: nil}, // makepkg --printsrcinfo
+	}
+	fakeRunner, commandRuns := testutils.MakeFakeCommandRunner(&commandRetvals)
+	calls := []string{}
+	vcsCheck := NewVCSCheck(fakeVCSChecker(upstream.VCSStatus{Head: "89abcde", Behind: 5}, nil, &calls), fakeRunner, vcsConfig(t, "{vcs: {refresh: true}}"))
+	action := NewCheckAction(nil, nil, vcsCheck, emptyCheckConfig)
+	pkg := vcsPackage(pkgPath)
+
+	result := action.Execute(&pkg)
+
+	assert.Equal(t, ActionSuccessStatus, result.GetStatus())
+	assert.Equal(t, "1.2.r3.g0123456 → 1.2.r8.g89abcde (5 commits behind)", result.String())
+	assert.Equal(t, upstream.Version("1.2.r8.g89abcde"), pkg.UpstreamVersion)
+	assert.True(t, pkg.IsOutdated)
+
+	// Both commands ran in the same temporary copy of the package
+	require.Len(t, *commandRuns, 2)
+	buildDir := (*commandRuns)[0].Cwd
+	assert.NotEqual(t, pkgPath, buildDir)
+	assert.Equal(t, "env", (*commandRuns)[0].Command)
+	assert.Equal(t, []string{"makepkg", "--nobuild", "--nodeps", "--skipinteg"}, (*commandRuns)[0].Args[2:])
+	assert.Equal(t, testutils.CommandRunnerParams{Cwd: buildDir, Command: "makepkg", Args: []string{"--printsrcinfo"}}, (*commandRuns)[1])
+	assert.NoDirExists(t, buildDir)
+	// The package itself is left intact
+	pkgbuild, _ := os.ReadFile(pkg.PkgbuildPath())
+	assert.Equal(t, "pkgver=1.2.r3.g0123456\n", string(pkgbuild))
+}
+
+func TestCheckAction_VCSMinBehind(t *testing.T) {
+	calls := []string{}
+	vcsCheck := NewVCSCheck(fakeVCSChecker(upstream.VCSStatus{Head: "89abcde", Behind: 5}, nil, &calls), nil, vcsConfig(t, "{vcs: {refresh: true, minBehind: 10}}"))
+	action := NewCheckAction(nil, nil, vcsCheck, emptyCheckConfig)
+	pkg := vcsPackage("")
+
+	result := action.Execute(&pkg)
+
+	// Refresh would use the nil command runner if it wasn't skipped
+	assert.Equal(t, ActionSuccessStatus, result.GetStatus())
+	assert.Equal(t, "1.2.r3.g0123456 (5 commits behind)", result.String())
+	assert.False(t, pkg.IsOutdated)
+}
+
+func TestCheckAction_VCSFailed(t *testing.T) {
+	calls := []string{}
+	failingCheck := NewVCSCheck(fakeVCSChecker(upstream.VCSStatus{}, errors.New("ls-remote failed"), &calls), nil, vcsConfig(t, "{}"))
+	noHashPkg := vcsPackage("")
+	noHashPkg.Pkgver = pack.Version("20230101")
+	noSourcePkg := vcsPackage("")
+	noSourcePkg.Source = []string{"foo.patch"}
+
+	action := NewCheckAction(nil, nil, failingCheck, emptyCheckConfig)
+
+	for _, pkg := range []pack.Package{vcsPackage(""), noHashPkg, noSourcePkg} {
+		result := action.Execute(&pkg)
+
+		assert.Equal(t, ActionFailedStatus, result.GetStatus())
+		assert.ErrorIs(t, result.GetError(), ErrCheckAction)
+		assert.Equal(t, "?", result.String())
+	}
+	assert.Len(t, calls, 1)
+}
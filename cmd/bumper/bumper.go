@@ -32,6 +32,8 @@ var (
 	configPath       = ""
 	completion       = ""
 	versionOverrides = []string{}
+	checkVCS         = false
+	keepFailed       = false
 	debug            = false
 )
 
@@ -57,49 +59,38 @@ enables you to run bumper in a dir containing multiple package dirs.`,
 	Example: `  bumper                                find and bump packages in $PWD
   bumper --override my-package=1.2.3    override my-package version to 1.2.3
   bumper --bump=false                   find packages, check updates in $PWD
+  bumper --vcs --bump=false             check how far VCS packages are behind
   bumper ~/workspace/aur                find and bump packages in given dir
   bumper ~/workspace/aur/my-package     bump single package`,
 	Version: "1.0.2",
-	Run: func(cmd *cobra.Command, args []string) {
+	Args:    cobra.ArbitraryArgs,
+	PersistentPreRun: func(_cmd *cobra.Command, _args []string) {
 		if debug {
 			bumper.EnableDebugLogging()
 		}
-
+	},
+	Run: func(cmd *cobra.Command, args []string) {
 		if completion != "" {
 			generateCompletion(cmd, completion)
 			os.Exit(0)
 		}
 
-		var workDir string
-		switch len(args) {
-		case 0:
-			workDir = "."
-		case 1:
-			workDir = args[0]
-		default:
-			cmd.PrintErr("Too many arguments, only one path allowed!")
-			os.Exit(1)
-		}
-
-		workDir, err := filepath.Abs(workDir)
-		if err != nil {
-			cmd.PrintErrf("Fatal error, invalid path: %v.\n", err)
-			os.Exit(1)
-		}
+		workDir := parseWorkDir(cmd, args)
 
 		bumperCLIConfig, err := configFromVersionOverrides(versionOverrides)
 		if err != nil {
 			fmt.Printf("Fatal error, invalid CLI option: %v.\n", err)
 			os.Exit(1)
 		}
+		bumperConfig := readConfig(bumperCLIConfig)
 
-		bumperConfig, err := bumper.ReadConfig(configPath, bumperCLIConfig)
+		httpClient, err := upstream.NewHTTPClient(bumperConfig.Get("http"), cmd.Version)
 		if err != nil {
 			fmt.Printf("Fatal error, invalid config: %v.\n", err)
 			os.Exit(1)
 		}
 
-		actions := createActions(doActions, bumperConfig)
+		actions := createActions(doActions, bumperConfig, httpClient)
 		runBumper(workDir, actions)
 	},
 	ValidArgsFunction: func(_cmd *cobra.Command, _args []string, _toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -109,42 +100,103 @@ enables you to run bumper in a dir containing multiple package dirs.`,
 
 func init() {
 	bumperCmd.Flags().BoolVarP(&doActions.bump, "bump", "b", true, "bump outdated packages")
-	bumperCmd.Flags().BoolVarP(&doActions.make, "make", "m", true, "build bumped packages")
-	bumperCmd.Flags().BoolVarP(&doActions.commit, "commit", "c", true, "commit changes")
-	bumperCmd.Flags().BoolVarP(&doActions.push, "push", "p", false, "push committed changes")
-	bumperCmd.Flags().IntVarP(&collectDepth, "depth", "d", 1, "depth of dir recursion in search for packages")
-	bumperCmd.Flags().StringVarP(&configPath, "config", "", "", "path to configuration file")
+	bumperCmd.PersistentFlags().BoolVarP(&doActions.make, "make", "m", true, "build bumped packages")
+	bumperCmd.PersistentFlags().BoolVarP(&doActions.commit, "commit", "c", true, "commit changes")
+	bumperCmd.PersistentFlags().BoolVarP(&doActions.push, "push", "p", false, "push committed changes")
+	bumperCmd.PersistentFlags().IntVarP(&collectDepth, "depth", "d", 1, "depth of dir recursion in search for packages")
+	bumperCmd.PersistentFlags().StringVarP(&configPath, "config", "", "", "path to configuration file")
 	bumperCmd.Flags().StringVarP(&completion, "completion", "", "", "generate completion for shell: bash, zsh, fish")
 	bumperCmd.Flags().StringArrayVarP(&versionOverrides, "override", "o", []string{}, "override upstream version, format: package=version")
-	bumperCmd.Flags().BoolVarP(&debug, "debug", "", false, "enable debug logging")
+	bumperCmd.Flags().BoolVarP(&checkVCS, "vcs", "", false, "check VCS packages against their upstream repos")
+	bumperCmd.PersistentFlags().BoolVarP(&keepFailed, "keep-failed", "", false, "keep changes of packages which failed to bump or build")
+	bumperCmd.PersistentFlags().BoolVarP(&debug, "debug", "", false, "enable debug logging")
 	bumperCmd.RegisterFlagCompletionFunc("completion", func(_cmd *cobra.Command, _args []string, _toComplete string) ([]string, cobra.ShellCompDirective) { //nolint:errcheck
 		return []string{"bash", "zsh", "fish"}, cobra.ShellCompDirectiveDefault
 	})
 }
 
-func createActions(doActions DoActions, bumperConfig config.Provider) []bumper.Action {
+// parseWorkDir returns absolute path of the dir given in args, or of the current dir if there's none.
+func parseWorkDir(cmd *cobra.Command, args []string) string {
+	var workDir string
+	switch len(args) {
+	case 0:
+		workDir = "."
+	case 1:
+		workDir = args[0]
+	default:
+		cmd.PrintErr("Too many arguments, only one path allowed!")
+		os.Exit(1)
+	}
+
+	workDir, err := filepath.Abs(workDir)
+	if err != nil {
+		cmd.PrintErrf("Fatal error, invalid path: %v.\n", err)
+		os.Exit(1)
+	}
+	return workDir
+}
+
+// readConfig reads the config file, overridden with the config from CLI options.
+func readConfig(cliConfig ...config.YAMLOption) config.Provider {
+	cliConfig = append(cliConfig, configFromFlags(checkVCS, keepFailed))
+	bumperConfig, err := bumper.ReadConfig(configPath, cliConfig...)
+	if err != nil {
+		fmt.Printf("Fatal error, invalid config: %v.\n", err)
+		os.Exit(1)
+	}
+	return bumperConfig
+}
+
+// createVCSCheck returns VCS check if it's enabled, nil otherwise.
+func createVCSCheck(checkConfig config.Value, httpClient *upstream.HTTPClient) *bumper.VCSCheck {
+	vcsConfig := checkConfig.Get("vcs")
+	var vcsEnabled bool
+	vcsConfig.Get("enabled").Populate(&vcsEnabled) //nolint:errcheck
+	if !vcsEnabled {
+		return nil
+	}
+	vcsChecker := upstream.NewVCSChecker(httpClient, bumper.ExecCommand, checkConfig.Get("providers"))
+	return bumper.NewVCSCheck(vcsChecker.Check, bumper.ExecCommand, vcsConfig)
+}
+
+func createActions(doActions DoActions, bumperConfig config.Provider, httpClient *upstream.HTTPClient) []bumper.Action {
+	// Plugins can still be configured explicitly if the directory is unknown
+	pluginsDir, _ := bumper.GetPluginsDir()
+	providerFactory := upstream.NewProviderFactory(httpClient, bumper.ExecCommandWithEnv, pluginsDir)
 	actions := []bumper.Action{
-		bumper.NewCheckAction(upstream.NewVersionProvider, bumperConfig.Get("check")),
+		bumper.NewCheckAction(
+			providerFactory.NewVersionProvider,
+			providerFactory.NewPluginProvider,
+			createVCSCheck(bumperConfig.Get("check"), httpClient),
+			bumperConfig.Get("check"),
+		),
 	}
 
 	if doActions.bump {
-		actions = append(actions, bumper.NewBumpAction(bumper.ExecCommand))
+		actions = append(actions, bumper.NewBumpAction(bumper.ExecCommand, bumperConfig.Get("bump")))
 	} else {
 		return actions
 	}
 
+	return appendFinishingActions(actions, doActions, bumperConfig)
+}
+
+// appendFinishingActions appends actions run after the package files are changed: make, commit and push.
+func appendFinishingActions(actions []bumper.Action, doActions DoActions, bumperConfig config.Provider) []bumper.Action {
 	if doActions.make {
 		actions = append(actions, bumper.NewMakeAction(bumper.ExecCommand))
 	}
 
+	// commit and push share the repositories, so they are found once
+	gitRepos := bumper.NewGitRepos(bumper.ExecCommand)
 	if doActions.commit {
-		actions = append(actions, bumper.NewCommitAction(bumper.ExecCommand, bumperConfig.Get("commit")))
+		actions = append(actions, bumper.NewCommitAction(bumper.ExecCommand, bumper.ExecCommandWithEnv, gitRepos, bumperConfig.Get("commit")))
 	} else {
 		return actions
 	}
 
 	if doActions.push {
-		actions = append(actions, bumper.NewPushAction(bumper.ExecCommand))
+		actions = append(actions, bumper.NewPushAction(bumper.ExecCommand, gitRepos))
 	}
 
 	return actions
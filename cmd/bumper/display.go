@@ -1,6 +1,7 @@
 package bumper
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"strings"
@@ -8,6 +9,7 @@ import (
 	"time"
 
 	"github.com/bcyran/bumper/bumper"
+	"github.com/bcyran/bumper/upstream"
 	"github.com/fatih/color"
 )
 
@@ -22,6 +24,7 @@ var (
 	failureColor  = color.New(color.FgRed).SprintFunc()
 	progressColor = color.New(color.FgYellow).SprintFunc()
 	skippedColor  = color.New(color.FgBlue).SprintFunc()
+	limitedColor  = color.New(color.FgMagenta).SprintFunc()
 )
 
 type Flusher interface {
@@ -92,8 +95,12 @@ func (pkgDisplay *PackageDisplay) String() string {
 	var pkgError error
 	if pkgDisplay.finished {
 		if pkgDisplay.failed {
-			bullet = failureColor("✗")
 			pkgError = pkgDisplay.actionResults[len(pkgDisplay.actionResults)-1].GetError()
+			if errors.Is(pkgError, upstream.ErrRateLimited) {
+				bullet = limitedColor("⧗")
+			} else {
+				bullet = failureColor("✗")
+			}
 		} else if pkgDisplay.skipped {
 			bullet = skippedColor("∅")
 		} else {
@@ -107,11 +114,24 @@ func (pkgDisplay *PackageDisplay) String() string {
 
 	pkgString := fmt.Sprintf("%s %s: %s", bullet, pkgDisplay.name, strings.Join(resultsStrings, ", "))
 	if pkgError != nil {
-		pkgString += failureColor(prependBracket(pkgError.Error()))
+		var rateLimitErr *upstream.RateLimitError
+		if errors.As(pkgError, &rateLimitErr) {
+			pkgString += limitedColor(prependBracket(rateLimitMessage(rateLimitErr) + lineSep + pkgError.Error()))
+		} else {
+			pkgString += failureColor(prependBracket(pkgError.Error()))
+		}
 	}
 	return pkgString
 }
 
+// rateLimitMessage returns a short summary of the rate limit error, including the reset time if known.
+func rateLimitMessage(rateLimitErr *upstream.RateLimitError) string {
+	if rateLimitErr.Reset.IsZero() {
+		return "rate limited, reset time unknown"
+	}
+	return fmt.Sprintf("rate limited until %s", rateLimitErr.Reset.Local().Format(time.TimeOnly))
+}
+
 // prependBracket prepends given string with unicode "bracket" drawing, like this:
 // │ some text
 // └ more text
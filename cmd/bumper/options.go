@@ -31,6 +31,18 @@ func configFromVersionOverrides(versionOverrides []string) (config.YAMLOption, e
 	return config.Static(checkConfig), nil
 }
 
+// configFromFlags sets config values enabled by CLI flags, the values are left unchanged if the flags are not set.
+func configFromFlags(checkVCS bool, keepFailed bool) config.YAMLOption {
+	flagsConfig := map[string]map[string]interface{}{}
+	if checkVCS {
+		flagsConfig["check"] = map[string]interface{}{"vcs": map[string]bool{"enabled": true}}
+	}
+	if keepFailed {
+		flagsConfig["bump"] = map[string]interface{}{"keepFailed": true}
+	}
+	return config.Static(flagsConfig)
+}
+
 func parseVersionOverrides(versionOverrides []string) (map[string]string, error) {
 	overridesMap := map[string]string{}
 
@@ -1,6 +1,7 @@
 package bumper
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -26,3 +27,20 @@ func TestConfigFromVersionOverrides_Fail(t *testing.T) {
 	assert.ErrorIs(t, err, ErrInvalidOverride)
 	assert.ErrorContains(t, err, "'invalidstring'")
 }
+
+func TestConfigFromFlags(t *testing.T) {
+	fileConfig := config.Source(strings.NewReader("{check: {vcs: {refresh: true}}}"))
+
+	enabledConfig, err := config.NewYAML(fileConfig, configFromFlags(true, true))
+	assert.Nil(t, err)
+	assert.Equal(t, "true", enabledConfig.Get("check.vcs.enabled").String())
+	assert.Equal(t, "true", enabledConfig.Get("check.vcs.refresh").String())
+	assert.Equal(t, "true", enabledConfig.Get("bump.keepFailed").String())
+
+	fileConfig = config.Source(strings.NewReader("{check: {vcs: {refresh: true}}}"))
+	unchangedConfig, err := config.NewYAML(fileConfig, configFromFlags(false, false))
+	assert.Nil(t, err)
+	assert.False(t, unchangedConfig.Get("check.vcs.enabled").HasValue())
+	assert.False(t, unchangedConfig.Get("bump.keepFailed").HasValue())
+	assert.Equal(t, "true", unchangedConfig.Get("check.vcs.refresh").String())
+}
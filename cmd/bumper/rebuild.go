@@ -0,0 +1,56 @@
+package bumper
+
+import (
+	"os"
+
+	"github.com/bcyran/bumper/bumper"
+	"github.com/spf13/cobra"
+	"go.uber.org/config"
+)
+
+var (
+	rebuildAgainst  = ""
+	rebuildPackages = []string{}
+)
+
+var rebuildCmd = &cobra.Command{
+	Use:   "rebuild [dir]",
+	Short: "Increment $pkgrel to rebuild packages",
+	Long: `Rebuild packages without changing their version, e.g. after soname bump
+of a dependency.
+
+Packages to rebuild are selected by dependency, with --against, or by pkgbase,
+with --package. For each of them pkgrel is incremented and .SRCINFO regenerated.
+Then the packages are built, committed and pushed, same as bumped packages.`,
+	Example: `  bumper rebuild --against python       rebuild packages depending on python
+  bumper rebuild --package my-package   rebuild my-package
+  bumper rebuild -a boost --make=false  rebuild without building`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if rebuildAgainst == "" && len(rebuildPackages) == 0 {
+			cmd.PrintErr("Packages to rebuild have to be selected with --against or --package!\n")
+			os.Exit(1)
+		}
+
+		workDir := parseWorkDir(cmd, args)
+		bumperConfig := readConfig()
+
+		actions := createRebuildActions(doActions, bumperConfig)
+		runBumper(workDir, actions)
+	},
+	ValidArgsFunction: func(_cmd *cobra.Command, _args []string, _toComplete string) ([]string, cobra.ShellCompDirective) {
+		return nil, cobra.ShellCompDirectiveFilterDirs
+	},
+}
+
+func init() {
+	rebuildCmd.Flags().StringVarP(&rebuildAgainst, "against", "a", "", "rebuild packages which depend on the given package")
+	rebuildCmd.Flags().StringArrayVarP(&rebuildPackages, "package", "P", []string{}, "rebuild package with the given pkgbase")
+	bumperCmd.AddCommand(rebuildCmd)
+}
+
+func createRebuildActions(doActions DoActions, bumperConfig config.Provider) []bumper.Action {
+	actions := []bumper.Action{
+		bumper.NewRebuildAction(bumper.ExecCommand, rebuildAgainst, rebuildPackages, bumperConfig.Get("bump")),
+	}
+	return appendFinishingActions(actions, doActions, bumperConfig)
+}
@@ -2,8 +2,12 @@ package testutils
 
 type CommandRunner = func(cwd string, command string, args ...string) ([]byte, error)
 
+type EnvCommandRunner = func(cwd string, env []string, command string, args ...string) ([]byte, error)
+
 type CommandRunnerParams struct {
-	Cwd     string
+	Cwd string
+	// Env holds the variables added to the environment, nil if the command was run without any
+	Env     []string
 	Command string
 	Args    []string
 }
@@ -17,10 +21,18 @@ type CommandRunnerRetval struct {
 // Instead it appends each call params to a slice for later assertions.
 // Each call returns stdout and err values from given retvals slice.
 func MakeFakeCommandRunner(retvals *[]CommandRunnerRetval) (CommandRunner, *[]CommandRunnerParams) {
+	fakeExecCommand, _, commandRuns := MakeFakeCommandRunners(retvals)
+	return fakeExecCommand, commandRuns
+}
+
+// MakeFakeCommandRunners creates fake CommandRunner and EnvCommandRunner, like MakeFakeCommandRunner.
+// Both of them append to the same slice and take the values from the same retvals slice.
+func MakeFakeCommandRunners(retvals *[]CommandRunnerRetval) (CommandRunner, EnvCommandRunner, *[]CommandRunnerParams) {
 	var commandRuns []CommandRunnerParams
-	fakeExecCommand := func(cwd string, command string, args ...string) ([]byte, error) {
+	fakeEnvExecCommand := func(cwd string, env []string, command string, args ...string) ([]byte, error) {
 		opts := CommandRunnerParams{
 			Cwd:     cwd,
+			Env:     env,
 			Command: command,
 			Args:    args,
 		}
@@ -29,5 +41,8 @@ func MakeFakeCommandRunner(retvals *[]CommandRunnerRetval) (CommandRunner, *[]Co
 		*retvals = (*retvals)[1:]
 		return retval.Stdout, retval.Err
 	}
-	return fakeExecCommand, &commandRuns
+	fakeExecCommand := func(cwd string, command string, args ...string) ([]byte, error) {
+		return fakeEnvExecCommand(cwd, nil, command, args...)
+	}
+	return fakeExecCommand, fakeEnvExecCommand, &commandRuns
 }
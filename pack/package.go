@@ -21,8 +21,22 @@ type Package struct {
 	*Srcinfo
 	Path            string
 	UpstreamVersion upstream.Version
-	IsOutdated      bool
-	IsVCS           bool
+	// UpstreamRawVersion is the upstream version string before normalisation, known only for outdated packages,
+	// if the provider supports it
+	UpstreamRawVersion string
+	// UpstreamEpoch is set if the upstream version is lower than the current one and requires epoch bump
+	UpstreamEpoch string
+	// UpstreamRevision is known only for outdated packages, if the provider supports it
+	UpstreamRevision upstream.Revision
+	// UpstreamProvider is the name of the provider which found the version, empty if the version is overridden
+	UpstreamProvider string
+	// UpstreamReleaseNotes are known only for outdated packages, if the provider supports it
+	UpstreamReleaseNotes string
+	IsOutdated           bool
+	IsVCS                bool
+	// IsRebuild is set for packages rebuilt with incremented pkgrel, RebuildAgainst holds the updated dependency
+	IsRebuild      bool
+	RebuildAgainst string
 }
 
 func (pkg *Package) PkgbuildPath() string {
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 )
 
@@ -23,6 +24,8 @@ func (v Version) GetVersionStr() string {
 }
 
 type FullVersion struct {
+	// Epoch is empty if it's not set in the package
+	Epoch  string
 	Pkgver Version
 	Pkgrel string
 }
@@ -31,9 +34,18 @@ type Srcinfo struct {
 	Pkgbase string
 	URL     string
 	Source  []string
+	// Depends and Makedepends hold names of the dependencies of all the split packages and architectures,
+	// without version constraints
+	Depends     []string
+	Makedepends []string
 	*FullVersion
 }
 
+// DependsOn checks whether the package has the given runtime or build dependency.
+func (srcinfo *Srcinfo) DependsOn(name string) bool {
+	return slices.Contains(srcinfo.Depends, name) || slices.Contains(srcinfo.Makedepends, name)
+}
+
 // ParseSrcinfo creates Srcinfo struct from .SRCINFO file at given path.
 func ParseSrcinfo(path string) (*Srcinfo, error) {
 	rawInfo, err := rawParseSrcinfo(path)
@@ -59,6 +71,11 @@ func ParseSrcinfo(path string) (*Srcinfo, error) {
 	if sourceValues, hasSourceValues := rawInfo["source"]; hasSourceValues {
 		srcinfo.Source = sourceValues
 	}
+	if epochValues, hasEpoch := rawInfo["epoch"]; hasEpoch {
+		srcinfo.Epoch = epochValues[0]
+	}
+	srcinfo.Depends = rawInfo.dependencyNames("depends")
+	srcinfo.Makedepends = rawInfo.dependencyNames("makedepends")
 
 	return &srcinfo, nil
 }
@@ -84,3 +101,25 @@ func rawParseSrcinfo(path string) (rawSrcinfo, error) {
 
 	return rawInfo, nil
 }
+
+// dependencyNames returns names of the dependencies from the given field and its architecture specific variants,
+// e.g. depends and depends_x86_64.
+func (rawInfo rawSrcinfo) dependencyNames(fieldName string) []string {
+	var names []string
+	for rawFieldName, values := range rawInfo {
+		if rawFieldName != fieldName && !strings.HasPrefix(rawFieldName, fieldName+"_") {
+			continue
+		}
+		for _, value := range values {
+			name, _, _ := strings.Cut(value, ":")
+			if index := strings.IndexAny(name, "<>="); index != -1 {
+				name = name[:index]
+			}
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	slices.Sort(names)
+	return names
+}
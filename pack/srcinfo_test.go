@@ -15,6 +15,7 @@ func TestParseSrcinfo_Valid(t *testing.T) {
 pkgbase = expected_base
         pkgname = expected_name
         url = expected_url
+        epoch = 2
         pkgver     = expected_ver
         pkgrel =     expected_rel
 		source = https://fake.source
@@ -30,6 +31,7 @@ pkgbase = expected_base
 		Pkgbase: "expected_base",
 		URL:     "expected_url",
 		FullVersion: &FullVersion{
+			Epoch:  "2",
 			Pkgver: Version("expected_ver"),
 			Pkgrel: "expected_rel",
 		},
@@ -54,3 +56,33 @@ pkgbase = expected_base
 	assert.ErrorIs(t, err, ErrInvalidSrcinfo)
 	assert.ErrorContains(t, err, "missing/invalid 'pkgver' value")
 }
+
+func TestParseSrcinfo_Depends(t *testing.T) {
+	srcinfoPath := filepath.Join(t.TempDir(), ".SRCINFO")
+	srcinfoText := []byte(`
+pkgbase = foo
+	url = https://foo.bar
+	pkgver = 1.0.0
+	pkgrel = 1
+	makedepends = cmake
+	makedepends_x86_64 = nasm>=2.14
+	depends = boost-libs
+	depends = icu: for unicode support
+
+pkgname = foo
+	depends = python>=3.11
+	depends = boost-libs
+
+pkgname = foo-docs
+`)
+	require.NoError(t, os.WriteFile(srcinfoPath, srcinfoText, 0o644))
+
+	parsedSrcinfo, err := ParseSrcinfo(srcinfoPath)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"boost-libs", "icu", "python"}, parsedSrcinfo.Depends)
+	assert.Equal(t, []string{"cmake", "nasm"}, parsedSrcinfo.Makedepends)
+	assert.True(t, parsedSrcinfo.DependsOn("python"))
+	assert.True(t, parsedSrcinfo.DependsOn("nasm"))
+	assert.False(t, parsedSrcinfo.DependsOn("py"))
+}
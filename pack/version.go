@@ -1,8 +1,11 @@
 package pack
 
 import (
+	"fmt"
 	"strconv"
-	"unicode"
+	"strings"
+
+	"github.com/bcyran/bumper/internal/vercmp"
 )
 
 // VersionLike is an interface for structs that can be treated as a version.
@@ -21,125 +24,28 @@ func VersionCmp(a, b VersionLike) int {
 // Returns 1 if a is newer than b, 0 if a and b are the same version, -1 if b is newer than a.
 // Tries to mimick behavior of rpmvercmp from libalpm: https://gitlab.archlinux.org/pacman/pacman/-/blob/master/lib/libalpm/version.c.
 func Rpmvercmp(a, b string) int {
-	var aSegStart, bSegStart, aSegEnd, bSegEnd int
-	aSegStart, bSegStart, aSegEnd, bSegEnd = 0, 0, 0, 0
-	var aSeg, bSeg string
-	var isNum bool
-
-	// Easy comparison to see if versions are identical.
-	if a == b {
-		return 0
-	}
-
-	// Loop through the version strings.
-	for aSegStart < len(a) && bSegStart < len(b) {
-		// Advance aSegStart and bSegStart to the next segment start -
-		// the next character that is not a separator.
-		for aSegStart < len(a) && !isAlNum(a[aSegStart]) {
-			aSegStart++
-		}
-		for bSegStart < len(b) && !isAlNum(b[bSegStart]) {
-			bSegStart++
-		}
-
-		// If we ran to the end of either strings, we are finished with the loop.
-		if aSegStart >= len(a) || bSegStart >= len(b) {
-			break
-		}
-
-		// If the separator lengths were different, we are also finished.
-		// aSegEnd and bSegEnd point at the PREVIOUS segment end at this point.
-		if (aSegStart - aSegEnd) < (bSegStart - bSegEnd) {
-			return -1
-		} else if (aSegStart - aSegEnd) > (bSegStart - bSegEnd) {
-			return 1
-		}
-
-		// Place aSegEnd and bSegEnd at the start of our current segments...
-		aSegEnd = aSegStart
-		bSegEnd = bSegStart
-
-		// ... and advance them to the end of the completely alpha or completely
-		// numeric substring. This is the end of the segment.
-		if isDigit(a[aSegEnd]) {
-			for aSegEnd < len(a) && isDigit(a[aSegEnd]) {
-				aSegEnd++
-			}
-			for bSegEnd < len(b) && isDigit(b[bSegEnd]) {
-				bSegEnd++
-			}
-			isNum = true
-		} else {
-			for aSegEnd < len(a) && isAlpha(a[aSegEnd]) {
-				aSegEnd++
-			}
-			for bSegEnd < len(b) && isAlpha(b[bSegEnd]) {
-				bSegEnd++
-			}
-			isNum = false
-		}
-
-		// If bSegEnd didn't advance at all it means the segments are of a different type.
-		// The numeric one wins.
-		if bSegEnd == bSegStart {
-			if isNum {
-				return 1
-			}
-			return -1
-		}
-
-		// Let's cut out our segments.
-		aSeg = a[aSegStart:aSegEnd]
-		bSeg = b[bSegStart:bSegEnd]
-
-		// Trim the leading zeros if they're are numeric.
-		if isNum {
-			aSegNum, _ := strconv.Atoi(aSeg)
-			bSegNum, _ := strconv.Atoi(bSeg)
-
-			if aSegNum > bSegNum {
-				return 1
-			} else if aSegNum < bSegNum {
-				return -1
-			}
-		} else {
-			if aSeg > bSeg {
-				return 1
-			} else if aSeg < bSeg {
-				return -1
-			}
-		}
+	return vercmp.Rpmvercmp(a, b)
+}
 
-		// Place aSegStart and bSegStart at the end of the current segment.
-		aSegStart = aSegEnd
-		bSegStart = bSegEnd
+// IncrementPkgrel returns pkgrel for a rebuild of the package.
+// Integer part is incremented and subrelease dropped, e.g. 1 → 2, 12 → 13, 1.1 → 2.
+func IncrementPkgrel(pkgrel string) (string, error) {
+	release, _, _ := strings.Cut(pkgrel, ".")
+	releaseNum, err := strconv.Atoi(release)
+	if err != nil || releaseNum < 0 {
+		return "", fmt.Errorf("invalid pkgrel '%s'", pkgrel)
 	}
+	return strconv.Itoa(releaseNum + 1), nil
+}
 
-	// This catches the case where all numeric and alpha segments have
-	// compare identically, but the segment separating characters were different.
-	if aSegStart >= len(a) && bSegStart >= len(b) {
-		return 0
+// IncrementEpoch returns epoch for a package whose version scheme has been reset, e.g. "" → 1, 1 → 2.
+func IncrementEpoch(epoch string) (string, error) {
+	if epoch == "" {
+		return "1", nil
 	}
-
-	// The final showdown. We never want a remaining alpha string to beat an empty string.
-	// The logic is a bit weird, but:
-	// - if there is nothing remaining in a, and remaining in b is not alpha, b is newer;
-	// - if remaining in a is alpha, b is newer;
-	// - otherwise a is newer.
-	if aSegStart >= len(a) && !isAlpha(b[bSegStart]) || aSegStart < len(a) && isAlpha(a[aSegStart]) {
-		return -1
+	epochNum, err := strconv.Atoi(epoch)
+	if err != nil || epochNum < 0 {
+		return "", fmt.Errorf("invalid epoch '%s'", epoch)
 	}
-	return 1
-}
-
-func isAlNum(b byte) bool {
-	return isDigit(b) || isAlpha(b)
-}
-
-func isDigit(b byte) bool {
-	return unicode.IsNumber(rune(b))
-}
-
-func isAlpha(b byte) bool {
-	return unicode.IsLetter(rune(b))
+	return strconv.Itoa(epochNum + 1), nil
 }
@@ -56,3 +56,43 @@ func assertVersionsComparison(t *testing.T, aVers, bVers []string, expected int)
 		}
 	}
 }
+
+func TestIncrementPkgrel(t *testing.T) {
+	cases := map[string]string{
+		"1":   "2",
+		"9":   "10",
+		"12":  "13",
+		"1.1": "2",
+		"3.2": "4",
+	}
+
+	for pkgrel, expected := range cases {
+		result, err := IncrementPkgrel(pkgrel)
+		assert.NoError(t, err, pkgrel)
+		assert.Equal(t, expected, result, pkgrel)
+	}
+
+	for _, invalid := range []string{"", "a", "-1", ".1"} {
+		_, err := IncrementPkgrel(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestIncrementEpoch(t *testing.T) {
+	cases := map[string]string{
+		"":  "1",
+		"1": "2",
+		"9": "10",
+	}
+
+	for epoch, expected := range cases {
+		result, err := IncrementEpoch(epoch)
+		assert.NoError(t, err, epoch)
+		assert.Equal(t, expected, result, epoch)
+	}
+
+	for _, invalid := range []string{"a", "-1", "1.1"} {
+		_, err := IncrementEpoch(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
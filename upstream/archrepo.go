@@ -0,0 +1,85 @@
+package upstream
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"go.uber.org/config"
+)
+
+const defaultArchRepoBaseURL = "https://archlinux.org"
+
+// archRepoProvider follows the version of a package from the official Arch Linux repositories.
+type archRepoProvider struct {
+	rawVersionRecorder
+	// repo is empty if the package can be in any repo
+	repo       string
+	pkgname    string
+	baseURL    string
+	httpClient *HTTPClient
+}
+
+type archRepoSearchResp struct {
+	Results []struct {
+		Pkgname string `json:"pkgname"`
+		Repo    string `json:"repo"`
+		Pkgver  string `json:"pkgver"`
+	} `json:"results"`
+}
+
+// newArchRepoProvider creates archRepoProvider if the package is configured to follow a repo package,
+// URL is irrelevant.
+func newArchRepoProvider(archRepoConfig config.Value, packageConfig config.Value, httpClient *HTTPClient) *archRepoProvider {
+	var follow followConfig
+	packageConfig.Get("follow").Populate(&follow) //nolint:errcheck
+	if follow.Repo == "" {
+		return nil
+	}
+
+	repo, pkgname, hasRepo := strings.Cut(follow.Repo, "/")
+	if !hasRepo {
+		repo, pkgname = "", follow.Repo
+	}
+
+	baseURL := defaultArchRepoBaseURL
+	archRepoConfig.Get("baseUrl").Populate(&baseURL) //nolint:errcheck
+
+	return &archRepoProvider{
+		repo:       strings.ToLower(repo),
+		pkgname:    pkgname,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: httpClient,
+	}
+}
+
+func (archRepo *archRepoProvider) searchURL() string {
+	return fmt.Sprintf("%s/packages/search/json/?name=%s", archRepo.baseURL, url.QueryEscape(archRepo.pkgname))
+}
+
+func (archRepo *archRepoProvider) Equal(other interface{}) bool {
+	switch other := other.(type) {
+	case *archRepoProvider:
+		return archRepo.repo == other.repo && archRepo.pkgname == other.pkgname && archRepo.baseURL == other.baseURL
+	default:
+		return false
+	}
+}
+
+func (archRepo *archRepoProvider) LatestVersion() (Version, error) {
+	var search archRepoSearchResp
+	if err := archRepo.httpClient.getJSON(archRepo.searchURL(), &search, nil); err != nil {
+		return "", err
+	}
+
+	for _, result := range search.Results {
+		if result.Pkgname != archRepo.pkgname || (archRepo.repo != "" && strings.ToLower(result.Repo) != archRepo.repo) {
+			continue
+		}
+		if version, isValid := archRepo.parseRawVersion(result.Pkgver); isValid {
+			return version, nil
+		}
+	}
+
+	return "", ErrVersionNotFound
+}
@@ -0,0 +1,86 @@
+package upstream
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"go.uber.org/config"
+)
+
+const defaultAURBaseURL = "https://aur.archlinux.org"
+
+// aurProvider follows the version of another AUR package.
+type aurProvider struct {
+	rawVersionRecorder
+	pkgname    string
+	baseURL    string
+	httpClient *HTTPClient
+}
+
+type aurInfoResp struct {
+	Results []struct {
+		Name    string `json:"Name"`
+		Version string `json:"Version"`
+	} `json:"results"`
+}
+
+// newAURProvider creates aurProvider if the package is configured to follow an AUR package, URL is irrelevant.
+func newAURProvider(aurConfig config.Value, packageConfig config.Value, httpClient *HTTPClient) *aurProvider {
+	var follow followConfig
+	packageConfig.Get("follow").Populate(&follow) //nolint:errcheck
+	if follow.AUR == "" {
+		return nil
+	}
+
+	baseURL := defaultAURBaseURL
+	aurConfig.Get("baseUrl").Populate(&baseURL) //nolint:errcheck
+
+	return &aurProvider{
+		pkgname:    follow.AUR,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: httpClient,
+	}
+}
+
+func (aur *aurProvider) infoURL() string {
+	return fmt.Sprintf("%s/rpc/v5/info?arg[]=%s", aur.baseURL, url.QueryEscape(aur.pkgname))
+}
+
+func (aur *aurProvider) Equal(other interface{}) bool {
+	switch other := other.(type) {
+	case *aurProvider:
+		return aur.pkgname == other.pkgname && aur.baseURL == other.baseURL
+	default:
+		return false
+	}
+}
+
+func (aur *aurProvider) LatestVersion() (Version, error) {
+	var info aurInfoResp
+	if err := aur.httpClient.getJSON(aur.infoURL(), &info, nil); err != nil {
+		return "", err
+	}
+
+	for _, result := range info.Results {
+		if result.Name != aur.pkgname {
+			continue
+		}
+		if version, isValid := aur.parseRawVersion(aurPkgver(result.Version)); isValid {
+			return version, nil
+		}
+	}
+
+	return "", ErrVersionNotFound
+}
+
+// aurPkgver extracts pkgver from the full AUR package version: [epoch:]pkgver-pkgrel.
+func aurPkgver(fullVersion string) string {
+	if _, withoutEpoch, hasEpoch := strings.Cut(fullVersion, ":"); hasEpoch {
+		fullVersion = withoutEpoch
+	}
+	if pkgrelStart := strings.LastIndex(fullVersion, "-"); pkgrelStart != -1 {
+		fullVersion = fullVersion[:pkgrelStart]
+	}
+	return fullVersion
+}
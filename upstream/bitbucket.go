@@ -0,0 +1,97 @@
+package upstream
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+
+	"go.uber.org/config"
+)
+
+const (
+	bitbucketAPIURL = "https://api.bitbucket.org/2.0"
+	// bitbucketMaxPages limits the number of tag pages checked, in case none of the tags matches.
+	bitbucketMaxPages = 5
+)
+
+var bitbucketURLRegex = regexp.MustCompile(`bitbucket\.org/([^/#?]+)/([^/#?]+?)(\.git)?(/|$|[#?])`)
+
+// bitbucketProvider tries to find the latest version in tags of a Bitbucket Cloud repo.
+type bitbucketProvider struct {
+	rawVersionRecorder
+	workspace   string
+	repo        string
+	username    string
+	appPassword string
+	filter      versionFilter
+	httpClient  *HTTPClient
+}
+
+type bitbucketTagsResp struct {
+	Values []struct {
+		Name string `json:"name"`
+	} `json:"values"`
+	Next string `json:"next"`
+}
+
+func newBitbucketProvider(url string, bitbucketConfig config.Value, filter versionFilter, httpClient *HTTPClient) *bitbucketProvider {
+	match := bitbucketURLRegex.FindStringSubmatch(url)
+	if len(match) == 0 {
+		return nil
+	}
+
+	provider := bitbucketProvider{workspace: match[1], repo: match[2], filter: filter, httpClient: httpClient}
+	bitbucketConfig.Get("username").Populate(&provider.username)       //nolint:errcheck
+	bitbucketConfig.Get("appPassword").Populate(&provider.appPassword) //nolint:errcheck
+
+	return &provider
+}
+
+func (bitbucket *bitbucketProvider) Equal(other interface{}) bool {
+	switch other := other.(type) {
+	case *bitbucketProvider:
+		return bitbucket.workspace == other.workspace && bitbucket.repo == other.repo
+	default:
+		return false
+	}
+}
+
+func (bitbucket *bitbucketProvider) apiHeaders() map[string]string {
+	headers := map[string]string{}
+	if bitbucket.username != "" && bitbucket.appPassword != "" {
+		credentials := base64.StdEncoding.EncodeToString([]byte(bitbucket.username + ":" + bitbucket.appPassword))
+		headers["Authorization"] = "Basic " + credentials
+	}
+	return headers
+}
+
+func (bitbucket *bitbucketProvider) tagsURL() string {
+	return fmt.Sprintf("%s/repositories/%s/%s/refs/tags?sort=-target.date&pagelen=100", bitbucketAPIURL, bitbucket.workspace, bitbucket.repo)
+}
+
+func (bitbucket *bitbucketProvider) LatestVersion() (Version, error) {
+	// Bitbucket has no releases, so there are no assets either
+	if bitbucket.filter.requiresAsset() {
+		return "", ErrVersionNotFound
+	}
+
+	pageURL := bitbucket.tagsURL()
+	for page := 0; page < bitbucketMaxPages && pageURL != ""; page++ {
+		var tags bitbucketTagsResp
+		if err := bitbucket.httpClient.getJSON(pageURL, &tags, bitbucket.apiHeaders()); err != nil {
+			return "", err
+		}
+
+		// Tags are sorted from the newest
+		for _, tag := range tags.Values {
+			if version, rawVersion, isValid := bitbucket.filter.parseTag(tag.Name); isValid {
+				bitbucket.latestRawVersion = rawVersion
+				return version, nil
+			}
+		}
+
+		pageURL = tags.Next
+	}
+
+	return "", ErrVersionNotFound
+}
@@ -0,0 +1,124 @@
+package upstream
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBitbucket_Valid(t *testing.T) {
+	cases := map[string]bitbucketProvider{
+		"https://bitbucket.org/foo/bar/get/v1.2.3.tar.gz": {workspace: "foo", repo: "bar"},
+		"https://bitbucket.org/foo/bar.git":               {workspace: "foo", repo: "bar"},
+		"https://bitbucket.org/foo/bar":                   {workspace: "foo", repo: "bar"},
+		"git+https://bitbucket.org/foo/bar-baz#tag=1.0":   {workspace: "foo", repo: "bar-baz"},
+	}
+
+	emptyConfig := testConfig(t, "{}")
+	for validURL, expectedResult := range cases {
+		expectedResult.httpClient = testHTTPClient
+		result := newBitbucketProvider(validURL, emptyConfig, versionFilter{}, testHTTPClient)
+		assert.Equal(t, &expectedResult, result, validURL)
+	}
+}
+
+func TestNewBitbucket_Invalid(t *testing.T) {
+	invalidURL := "https://github.com/foo/bar"
+
+	result := newBitbucketProvider(invalidURL, testConfig(t, "{}"), versionFilter{}, testHTTPClient)
+	assert.Nil(t, result)
+}
+
+func TestNewBitbucket_Config(t *testing.T) {
+	bitbucketConfig := testConfig(t, "{test: {username: john, appPassword: secret}}")
+
+	result := newBitbucketProvider("https://bitbucket.org/foo/bar", bitbucketConfig, versionFilter{}, testHTTPClient)
+
+	assert.Equal(t, "john", result.username)
+	assert.Equal(t, "secret", result.appPassword)
+}
+
+func TestBitbucketLatestVersion(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.bitbucket.org").
+		Get("/2.0/repositories/foo/bar/refs/tags").
+		MatchParam("sort", "-target.date").
+		MatchHeader("Authorization", "Basic am9objpzZWNyZXQ=").
+		Reply(200).
+		JSON(map[string]interface{}{
+			"values": []map[string]string{{"name": "nightly"}, {"name": "v1.2.3"}, {"name": "v1.2.2"}},
+		})
+
+	bitbucket := bitbucketProvider{workspace: "foo", repo: "bar", username: "john", appPassword: "secret", httpClient: testHTTPClient}
+
+	result, err := bitbucket.LatestVersion()
+
+	assert.NoError(t, err)
+	assert.Equal(t, Version("1.2.3"), result)
+}
+
+func TestBitbucketLatestVersion_Pagination(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.bitbucket.org").
+		Get("/2.0/repositories/foo/bar/refs/tags").
+		MatchParam("sort", "-target.date").
+		Reply(200).
+		JSON(map[string]interface{}{
+			"values": []map[string]string{{"name": "sdk-v2.0.0"}},
+			"next":   "https://api.bitbucket.org/2.0/repositories/foo/bar/refs/tags?page=2",
+		})
+	gock.New("https://api.bitbucket.org").
+		Get("/2.0/repositories/foo/bar/refs/tags").
+		MatchParam("page", "2").
+		Reply(200).
+		JSON(map[string]interface{}{
+			"values": []map[string]string{{"name": "cli-v1.5.0"}},
+		})
+
+	filter := versionFilter{tagPattern: regexp.MustCompile(`^cli-v(?P<version>.+)$`)}
+	bitbucket := bitbucketProvider{workspace: "foo", repo: "bar", filter: filter, httpClient: testHTTPClient}
+
+	result, err := bitbucket.LatestVersion()
+
+	assert.NoError(t, err)
+	assert.Equal(t, Version("1.5.0"), result)
+	assert.True(t, gock.IsDone())
+}
+
+func TestBitbucketLatestVersion_NoTags(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.bitbucket.org").
+		Get("/2.0/repositories/foo/bar/refs/tags").
+		Reply(200).
+		JSON(map[string]interface{}{"values": []interface{}{}})
+
+	bitbucket := bitbucketProvider{workspace: "foo", repo: "bar", httpClient: testHTTPClient}
+
+	_, err := bitbucket.LatestVersion()
+
+	assert.ErrorIs(t, err, ErrVersionNotFound)
+}
+
+func TestBitbucketLatestVersion_4xx(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.bitbucket.org").
+		Get("/2.0/repositories/foo/bar/refs/tags").
+		Reply(404).
+		JSON(map[string]interface{}{"type": "error"})
+
+	bitbucket := bitbucketProvider{workspace: "foo", repo: "bar", httpClient: testHTTPClient}
+
+	_, err := bitbucket.LatestVersion()
+
+	assert.ErrorIs(t, err, ErrVersionNotFound)
+}
+
+func TestBitbucketEqual(t *testing.T) {
+	bitbucket := &bitbucketProvider{workspace: "foo", repo: "bar"}
+
+	assert.True(t, bitbucket.Equal(&bitbucketProvider{workspace: "foo", repo: "bar"}))
+	assert.False(t, bitbucket.Equal(&bitbucketProvider{workspace: "foo", repo: "baz"}))
+	assert.False(t, bitbucket.Equal(&gitHubProvider{owner: "foo", repo: "bar"}))
+}
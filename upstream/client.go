@@ -0,0 +1,222 @@
+package upstream
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"go.uber.org/config"
+)
+
+const (
+	defaultTimeout = 30 * time.Second
+	// maxIdleConnsPerHost is raised from the default of 2, because most of the requests go to just a few API hosts.
+	maxIdleConnsPerHost = 16
+)
+
+var ErrInvalidHTTPConfig = errors.New("invalid HTTP configuration")
+
+// HTTPClient is used by all the version providers to talk to upstream APIs.
+// It's safe for concurrent use and should be shared, so the connections and rate limit state are reused.
+type HTTPClient struct {
+	client     *http.Client
+	userAgent  string
+	hostPauses *hostPauseRegistry
+	now        func() time.Time
+	sleep      func(time.Duration)
+}
+
+// NewHTTPClient creates HTTPClient based on the given config.
+// Supported settings are 'timeout' (duration string), 'proxy' (URL, environment proxy settings are used
+// if empty) and 'caBundle' (path to PEM file with extra trusted certificates).
+// Given bumper version is used in the User-Agent header.
+func NewHTTPClient(httpConfig config.Value, version string) (*HTTPClient, error) {
+	var rawConfig struct {
+		Timeout  string `yaml:"timeout"`
+		Proxy    string `yaml:"proxy"`
+		CABundle string `yaml:"caBundle"`
+	}
+	if err := httpConfig.Populate(&rawConfig); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidHTTPConfig, err)
+	}
+
+	timeout := defaultTimeout
+	if rawConfig.Timeout != "" {
+		var err error
+		if timeout, err = time.ParseDuration(rawConfig.Timeout); err != nil {
+			return nil, fmt.Errorf("%w: invalid timeout: %w", ErrInvalidHTTPConfig, err)
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+
+	if rawConfig.Proxy != "" {
+		proxyURL, err := url.Parse(rawConfig.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid proxy: %w", ErrInvalidHTTPConfig, err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if rawConfig.CABundle != "" {
+		rootCAs, err := loadCABundle(rawConfig.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidHTTPConfig, err)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: rootCAs, MinVersion: tls.VersionTLS12}
+	}
+
+	return newHTTPClient(&http.Client{Transport: transport, Timeout: timeout}, "bumper/"+version), nil
+}
+
+func newHTTPClient(client *http.Client, userAgent string) *HTTPClient {
+	return &HTTPClient{
+		client:     client,
+		userAgent:  userAgent,
+		hostPauses: newHostPauseRegistry(),
+		now:        time.Now,
+		sleep:      time.Sleep,
+	}
+}
+
+// loadCABundle returns system cert pool extended with the certificates from the given PEM file.
+func loadCABundle(path string) (*x509.CertPool, error) {
+	pemCerts, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("CA bundle reading error: %w", err)
+	}
+
+	rootCAs, err := x509.SystemCertPool()
+	if err != nil {
+		rootCAs = x509.NewCertPool()
+	}
+	if !rootCAs.AppendCertsFromPEM(pemCerts) {
+		return nil, fmt.Errorf("no certificates found in CA bundle %s", path)
+	}
+
+	return rootCAs, nil
+}
+
+// getJSON sends HTTP GET request to the given URL and writes JSON response to target struct.
+// Returns error if the request of JSON decoding fails.
+func (client *HTTPClient) getJSON(url string, target interface{}, headers map[string]string) error {
+	return client.requestJSON("GET", url, nil, target, headers)
+}
+
+// postJSON sends HTTP POST request with JSON encoded body to the given URL and writes JSON response to target struct.
+// Returns error if the request of JSON decoding fails.
+func (client *HTTPClient) postJSON(url string, body interface{}, target interface{}, headers map[string]string) error {
+	encodedBody, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("%w: POST %s %s", ErrRequestError, url, err)
+	}
+	postHeaders := map[string]string{"Content-Type": "application/json"}
+	for header, value := range headers {
+		postHeaders[header] = value
+	}
+	return client.requestJSON("POST", url, encodedBody, target, postHeaders)
+}
+
+// getXML sends HTTP GET request to the given URL and writes XML response to target struct.
+// Returns error if the request of XML decoding fails.
+func (client *HTTPClient) getXML(url string, target interface{}, headers map[string]string) error {
+	return client.request("GET", url, nil, headers, func(body io.Reader) error {
+		return xml.NewDecoder(body).Decode(target)
+	})
+}
+
+// getText sends HTTP GET request to the given URL and returns the response body as a string.
+func (client *HTTPClient) getText(url string, headers map[string]string) (string, error) {
+	var text string
+	err := client.request("GET", url, nil, headers, func(body io.Reader) error {
+		rawText, err := io.ReadAll(body)
+		text = string(rawText)
+		return err
+	})
+	return text, err
+}
+
+// requestJSON sends HTTP request to the given URL and writes JSON response to target struct.
+func (client *HTTPClient) requestJSON(method string, url string, body []byte, target interface{}, headers map[string]string) error {
+	return client.request(method, url, body, headers, func(body io.Reader) error {
+		return json.NewDecoder(body).Decode(&target)
+	})
+}
+
+// request sends HTTP request to the given URL and passes the body of successful response to decode function.
+// Requests failing with 5xx or 429 status are retried with exponential backoff.
+// If the API reports exhausted rate limit, further requests to the same host are paused until the limit
+// reset, provided the wait is short enough. Otherwise ErrRateLimited is returned.
+func (client *HTTPClient) request(method string, url string, body []byte, headers map[string]string, decode func(io.Reader) error) error {
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequest(method, url, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("%w: %s %s %s", ErrRequestError, method, url, err)
+		}
+
+		req.Header.Set("User-Agent", client.userAgent)
+		for header, value := range headers {
+			req.Header.Set(header, value)
+		}
+
+		client.waitForHost(req.URL.Host)
+
+		resp, err := client.client.Do(req)
+		if err != nil {
+			return fmt.Errorf("%w: %s %s %s", ErrRequestError, method, url, err)
+		}
+
+		limit := parseRateLimit(resp.Header, client.now())
+		if limit.isExhausted() && limit.isResetReasonable(client.now()) {
+			client.hostPauses.pause(req.URL.Host, limit.reset)
+		}
+
+		if isRateLimited(resp, limit) {
+			resp.Body.Close()
+			retryAt := limit.reset
+			if retryAt.IsZero() {
+				// The API didn't tell us when to come back, fall back to regular backoff
+				retryAt = client.now().Add(retryDelay(attempt))
+			}
+			if attempt < maxRetries && retryAt.Sub(client.now()) <= maxRateLimitWait {
+				client.hostPauses.pause(req.URL.Host, retryAt)
+				continue
+			}
+			return &RateLimitError{URL: url, Reset: limit.reset}
+		}
+
+		if resp.StatusCode >= http.StatusInternalServerError {
+			resp.Body.Close()
+			if attempt < maxRetries {
+				client.sleep(retryDelay(attempt))
+				continue
+			}
+			return fmt.Errorf("%w: %s %s status %d", ErrProviderError, method, url, resp.StatusCode)
+		}
+
+		defer resp.Body.Close()
+
+		if resp.StatusCode != 200 {
+			return fmt.Errorf("%w: %s %s status %d", ErrVersionNotFound, method, url, resp.StatusCode)
+		}
+
+		return decode(resp.Body)
+	}
+}
+
+// waitForHost blocks until requests to the host are allowed.
+func (client *HTTPClient) waitForHost(host string) {
+	if waitTime := client.hostPauses.pausedUntil(host).Sub(client.now()); waitTime > 0 {
+		client.sleep(waitTime)
+	}
+}
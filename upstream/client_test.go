@@ -0,0 +1,155 @@
+package upstream
+
+import (
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/config"
+)
+
+// testHTTPClient uses the default transport, so its requests are intercepted by gock.
+var testHTTPClient = newHTTPClient(&http.Client{}, "bumper/test")
+
+// newFakeClockHTTPClient creates HTTPClient with a fake clock starting at fakeNow, which advances only when sleeping.
+// Returns pointer to the slice of all the sleeps.
+func newFakeClockHTTPClient(t *testing.T) (*HTTPClient, *[]time.Duration) {
+	t.Helper()
+	now := fakeNow
+	sleeps := []time.Duration{}
+	client := newHTTPClient(&http.Client{}, "bumper/test")
+	client.now = func() time.Time { return now }
+	client.sleep = func(duration time.Duration) {
+		sleeps = append(sleeps, duration)
+		now = now.Add(duration)
+	}
+	return client, &sleeps
+}
+
+func httpConfig(t *testing.T, yaml string) config.Value {
+	t.Helper()
+	provider, err := config.NewYAML(config.Source(strings.NewReader(yaml)))
+	require.NoError(t, err)
+	return provider.Get("http")
+}
+
+func TestNewHTTPClient_Defaults(t *testing.T) {
+	client, err := NewHTTPClient(httpConfig(t, "{}"), "1.2.3")
+
+	require.NoError(t, err)
+	assert.Equal(t, "bumper/1.2.3", client.userAgent)
+	assert.Equal(t, defaultTimeout, client.client.Timeout)
+}
+
+func TestNewHTTPClient_Timeout(t *testing.T) {
+	client, err := NewHTTPClient(httpConfig(t, "{http: {timeout: 5s}}"), "1.2.3")
+
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, client.client.Timeout)
+}
+
+func TestNewHTTPClient_Invalid(t *testing.T) {
+	cases := []string{
+		"{http: {timeout: foo}}",
+		"{http: {proxy: ':/invalid'}}",
+		"{http: {caBundle: /does/not/exist.pem}}",
+	}
+
+	for _, yaml := range cases {
+		_, err := NewHTTPClient(httpConfig(t, yaml), "1.2.3")
+		assert.ErrorIs(t, err, ErrInvalidHTTPConfig)
+	}
+}
+
+func TestHTTPClientGetJSON_UserAgent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"userAgent": "%s"}`, r.Header.Get("User-Agent"))
+	}))
+	defer server.Close()
+	client, err := NewHTTPClient(httpConfig(t, "{}"), "1.2.3")
+	require.NoError(t, err)
+
+	var result map[string]string
+	err = client.getJSON(server.URL, &result, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"userAgent": "bumper/1.2.3"}, result)
+}
+
+func TestHTTPClientGetText(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "v1.0.0\nv1.1.0\n")
+	}))
+	defer server.Close()
+	client, err := NewHTTPClient(httpConfig(t, "{}"), "1.2.3")
+	require.NoError(t, err)
+
+	result, err := client.getText(server.URL, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.0.0\nv1.1.0\n", result)
+}
+
+func TestHTTPClientGetXML(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<?xml version="1.0"?><root><item>foo</item><item>bar</item></root>`)
+	}))
+	defer server.Close()
+	client, err := NewHTTPClient(httpConfig(t, "{}"), "1.2.3")
+	require.NoError(t, err)
+
+	var result struct {
+		Items []string `xml:"item"`
+	}
+	err = client.getXML(server.URL, &result, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"foo", "bar"}, result.Items)
+}
+
+func TestHTTPClientGetJSON_Proxy(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"proxiedURL": "%s"}`, r.URL.String())
+	}))
+	defer proxy.Close()
+	client, err := NewHTTPClient(httpConfig(t, fmt.Sprintf("{http: {proxy: '%s'}}", proxy.URL)), "1.2.3")
+	require.NoError(t, err)
+
+	var result map[string]string
+	err = client.getJSON("http://upstream.invalid/foo", &result, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"proxiedURL": "http://upstream.invalid/foo"}, result)
+}
+
+func TestHTTPClientGetJSON_CABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _r *http.Request) {
+		fmt.Fprint(w, `{"foo": "bar"}`)
+	}))
+	defer server.Close()
+	caBundlePath := filepath.Join(t.TempDir(), "ca.pem")
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caBundlePath, caBundle, 0o644))
+
+	var result map[string]string
+
+	// without the bundle the server certificate is not trusted
+	client, err := NewHTTPClient(httpConfig(t, "{}"), "1.2.3")
+	require.NoError(t, err)
+	err = client.getJSON(server.URL, &result, nil)
+	assert.ErrorIs(t, err, ErrRequestError)
+
+	client, err = NewHTTPClient(httpConfig(t, fmt.Sprintf("{http: {caBundle: '%s'}}", caBundlePath)), "1.2.3")
+	require.NoError(t, err)
+	err = client.getJSON(server.URL, &result, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"foo": "bar"}, result)
+}
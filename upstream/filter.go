@@ -0,0 +1,110 @@
+package upstream
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+
+	"go.uber.org/config"
+)
+
+const tagPatternVersionGroup = "version"
+
+var defaultFileVersionPattern = regexp.MustCompile(`\d+(\.\d+)+`)
+
+// versionFilter selects releases, tags and files according to the package configuration.
+type versionFilter struct {
+	// tagPattern, if set, has to match the tag for it to be considered.
+	// Version is taken from the 'version' named group, or the whole match if there's no such group.
+	tagPattern *regexp.Regexp
+	// assetPattern, if set, is a glob which has to match at least one of the release assets.
+	assetPattern string
+	// filePattern, if set, has to match the path of a released file for it to be considered.
+	// Version is taken from the 'version' named group, or the whole match if there's no such group.
+	filePattern *regexp.Regexp
+}
+
+func newVersionFilter(packageConfig config.Value) (versionFilter, error) {
+	filter := versionFilter{}
+
+	var tagPattern string
+	packageConfig.Get("tagPattern").Populate(&tagPattern) //nolint:errcheck
+	if tagPattern != "" {
+		var err error
+		if filter.tagPattern, err = regexp.Compile(tagPattern); err != nil {
+			return filter, fmt.Errorf("%w: invalid tag pattern: %w", ErrInvalidPackageConfig, err)
+		}
+	}
+
+	var filePattern string
+	packageConfig.Get("filePattern").Populate(&filePattern) //nolint:errcheck
+	if filePattern != "" {
+		var err error
+		if filter.filePattern, err = regexp.Compile(filePattern); err != nil {
+			return filter, fmt.Errorf("%w: invalid file pattern: %w", ErrInvalidPackageConfig, err)
+		}
+	}
+
+	packageConfig.Get("assetPattern").Populate(&filter.assetPattern) //nolint:errcheck
+	if _, err := path.Match(filter.assetPattern, ""); err != nil {
+		return filter, fmt.Errorf("%w: invalid asset pattern: %w", ErrInvalidPackageConfig, err)
+	}
+
+	return filter, nil
+}
+
+// parseTag makes Version from a tag or release name, honouring the tag pattern.
+// Returns also the part of the tag the version was made from.
+func (filter versionFilter) parseTag(tag string) (Version, string, bool) {
+	if filter.tagPattern == nil {
+		version, isValid := ParseVersion(tag)
+		return version, tag, isValid
+	}
+
+	return parsePatternVersion(filter.tagPattern, tag)
+}
+
+// parseFilePath makes Version from a path of a released file, honouring the file pattern.
+// Without the pattern, version is the first dot separated sequence of numbers in the file name.
+// Returns also the part of the path the version was made from.
+func (filter versionFilter) parseFilePath(filePath string) (Version, string, bool) {
+	if filter.filePattern == nil {
+		return parsePatternVersion(defaultFileVersionPattern, path.Base(filePath))
+	}
+	return parsePatternVersion(filter.filePattern, filePath)
+}
+
+// parsePatternVersion makes Version from the 'version' named group of the pattern match,
+// or the whole match if there's no such group. Returns also the matched string.
+func parsePatternVersion(pattern *regexp.Regexp, str string) (Version, string, bool) {
+	match := pattern.FindStringSubmatch(str)
+	if match == nil {
+		return Version(""), "", false
+	}
+	rawVersion := match[0]
+	if groupIndex := pattern.SubexpIndex(tagPatternVersionGroup); groupIndex != -1 {
+		rawVersion = match[groupIndex]
+	}
+	version, isValid := ParseVersion(rawVersion)
+	return version, rawVersion, isValid
+}
+
+// requiresAsset checks whether only releases with matching assets are accepted.
+// Tags don't have assets, so they are never accepted in this case.
+func (filter versionFilter) requiresAsset() bool {
+	return filter.assetPattern != ""
+}
+
+// hasRequiredAsset checks whether any of the asset names matches the asset pattern.
+// Always true if there's no asset pattern.
+func (filter versionFilter) hasRequiredAsset(assetNames []string) bool {
+	if !filter.requiresAsset() {
+		return true
+	}
+	for _, assetName := range assetNames {
+		if isMatch, _ := path.Match(filter.assetPattern, assetName); isMatch {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package upstream
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/config"
+)
+
+// testConfig parses given YAML and returns its 'test' section.
+func testConfig(t *testing.T, yaml string) config.Value {
+	t.Helper()
+	provider, err := config.NewYAML(config.Source(strings.NewReader(yaml)))
+	require.NoError(t, err)
+	return provider.Get("test")
+}
+
+func TestNewVersionFilter(t *testing.T) {
+	filter, err := newVersionFilter(testConfig(t, `{test: {tagPattern: '^cli-v(?P<version>.+)$', assetPattern: '*.tar.gz'}}`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, versionFilter{tagPattern: regexp.MustCompile(`^cli-v(?P<version>.+)$`), assetPattern: "*.tar.gz"}, filter)
+}
+
+func TestNewVersionFilter_Empty(t *testing.T) {
+	filter, err := newVersionFilter(testConfig(t, "{}"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, versionFilter{}, filter)
+}
+
+func TestNewVersionFilter_Invalid(t *testing.T) {
+	cases := []string{
+		`{test: {tagPattern: '(unclosed'}}`,
+		`{test: {assetPattern: '[unclosed'}}`,
+		`{test: {filePattern: '(unclosed'}}`,
+	}
+
+	for _, yaml := range cases {
+		_, err := newVersionFilter(testConfig(t, yaml))
+		assert.ErrorIs(t, err, ErrInvalidPackageConfig)
+	}
+}
+
+func TestVersionFilterParseTag(t *testing.T) {
+	cases := []struct {
+		tagPattern      string
+		tag             string
+		expectedVersion Version
+		expectedValid   bool
+	}{
+		{tagPattern: "", tag: "v1.2.3", expectedVersion: "1.2.3", expectedValid: true},
+		{tagPattern: "", tag: "cli-v1.2.3", expectedVersion: "", expectedValid: false},
+		{tagPattern: `^cli-v(?P<version>.+)$`, tag: "cli-v1.2.3", expectedVersion: "1.2.3", expectedValid: true},
+		{tagPattern: `^cli-v(?P<version>.+)$`, tag: "sdk-v4.0.0", expectedVersion: "", expectedValid: false},
+		{tagPattern: `^cli-v(?P<version>.+)$`, tag: "cli-vfoo-bar", expectedVersion: "", expectedValid: false},
+		{tagPattern: `\d+\.\d+`, tag: "release 1.2 final", expectedVersion: "1.2", expectedValid: true},
+	}
+
+	for _, testCase := range cases {
+		filter := versionFilter{}
+		if testCase.tagPattern != "" {
+			filter.tagPattern = regexp.MustCompile(testCase.tagPattern)
+		}
+
+		version, _, isValid := filter.parseTag(testCase.tag)
+
+		assert.Equal(t, testCase.expectedValid, isValid, testCase.tag)
+		assert.Equal(t, testCase.expectedVersion, version, testCase.tag)
+	}
+}
+
+func TestVersionFilterParseFilePath(t *testing.T) {
+	cases := []struct {
+		filePattern     string
+		filePath        string
+		expectedVersion Version
+		expectedValid   bool
+	}{
+		{filePattern: "", filePath: "/foo/1.2.3/foo-1.2.3.tar.gz", expectedVersion: "1.2.3", expectedValid: true},
+		{filePattern: "", filePath: "/foo2/v2/foo2-2.10-src.tar.gz", expectedVersion: "2.10", expectedValid: true},
+		{filePattern: "", filePath: "/foo/README", expectedVersion: "", expectedValid: false},
+		{filePattern: `/foo-cli-(?P<version>[\d.]+)\.tar\.gz$`, filePath: "/foo/1.2/foo-cli-1.2.tar.gz", expectedVersion: "1.2", expectedValid: true},
+		{filePattern: `/foo-cli-(?P<version>[\d.]+)\.tar\.gz$`, filePath: "/foo/1.2/foo-gui-1.2.tar.gz", expectedVersion: "", expectedValid: false},
+	}
+
+	for _, testCase := range cases {
+		filter := versionFilter{}
+		if testCase.filePattern != "" {
+			filter.filePattern = regexp.MustCompile(testCase.filePattern)
+		}
+
+		version, _, isValid := filter.parseFilePath(testCase.filePath)
+
+		assert.Equal(t, testCase.expectedValid, isValid, testCase.filePath)
+		assert.Equal(t, testCase.expectedVersion, version, testCase.filePath)
+	}
+}
+
+func TestVersionFilterHasRequiredAsset(t *testing.T) {
+	assets := []string{"foo-linux-aarch64.tar.gz", "foo-linux-x86_64.tar.gz"}
+
+	assert.True(t, versionFilter{}.hasRequiredAsset(nil))
+	assert.True(t, versionFilter{assetPattern: "*-linux-x86_64.tar.gz"}.hasRequiredAsset(assets))
+	assert.False(t, versionFilter{assetPattern: "*-darwin-*.tar.gz"}.hasRequiredAsset(assets))
+	assert.False(t, versionFilter{assetPattern: "*.tar.gz"}.hasRequiredAsset(nil))
+}
@@ -0,0 +1,9 @@
+package upstream
+
+// followConfig holds the package config selecting another package, whose version is followed.
+type followConfig struct {
+	// AUR is a name of the AUR package
+	AUR string `yaml:"aur"`
+	// Repo is a name of the official repos package, optionally prefixed with the repo, e.g. extra/foo
+	Repo string `yaml:"repo"`
+}
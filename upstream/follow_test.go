@@ -0,0 +1,134 @@
+package upstream
+
+import (
+	"testing"
+
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewArchRepo(t *testing.T) {
+	cases := map[string]*archRepoProvider{
+		"{test: {follow: {repo: extra/foo}}}": {repo: "extra", pkgname: "foo"},
+		"{test: {follow: {repo: Core/foo}}}":  {repo: "core", pkgname: "foo"},
+		"{test: {follow: {repo: foo}}}":       {pkgname: "foo"},
+		"{test: {follow: {aur: foo}}}":        nil,
+		"{test: {}}":                          nil,
+	}
+
+	emptyConfig := testConfig(t, "{}")
+	for packageConfigYAML, expectedResult := range cases {
+		if expectedResult != nil {
+			expectedResult.baseURL = defaultArchRepoBaseURL
+			expectedResult.httpClient = testHTTPClient
+		}
+		result := newArchRepoProvider(emptyConfig, testConfig(t, packageConfigYAML), testHTTPClient)
+		assert.Equal(t, expectedResult, result, packageConfigYAML)
+	}
+}
+
+func TestArchRepoLatestVersion(t *testing.T) {
+	defer gock.Off()
+	gock.New("http://localhost:8000").
+		Get("/packages/search/json/").
+		MatchParam("name", "foo").
+		Reply(200).
+		JSON(map[string]interface{}{
+			"results": []map[string]interface{}{
+				{"pkgname": "foo", "repo": "extra-testing", "pkgver": "1.3.0", "pkgrel": "1", "epoch": 0},
+				{"pkgname": "foo", "repo": "extra", "pkgver": "1.2.3", "pkgrel": "2", "epoch": 1},
+			},
+		})
+
+	archRepo := archRepoProvider{repo: "extra", pkgname: "foo", baseURL: "http://localhost:8000", httpClient: testHTTPClient}
+
+	result, err := archRepo.LatestVersion()
+
+	assert.NoError(t, err)
+	assert.Equal(t, Version("1.2.3"), result)
+}
+
+func TestArchRepoLatestVersion_NotFound(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://archlinux.org").
+		Get("/packages/search/json/").
+		Reply(200).
+		JSON(map[string]interface{}{
+			"results": []map[string]interface{}{
+				{"pkgname": "foo-docs", "repo": "extra", "pkgver": "1.2.3"},
+			},
+		})
+
+	archRepo := archRepoProvider{pkgname: "foo", baseURL: defaultArchRepoBaseURL, httpClient: testHTTPClient}
+
+	_, err := archRepo.LatestVersion()
+
+	assert.ErrorIs(t, err, ErrVersionNotFound)
+}
+
+func TestArchRepoEqual(t *testing.T) {
+	archRepo := &archRepoProvider{repo: "extra", pkgname: "foo"}
+
+	assert.True(t, archRepo.Equal(&archRepoProvider{repo: "extra", pkgname: "foo"}))
+	assert.False(t, archRepo.Equal(&archRepoProvider{repo: "core", pkgname: "foo"}))
+	assert.False(t, archRepo.Equal(&aurProvider{pkgname: "foo"}))
+}
+
+func TestNewAUR(t *testing.T) {
+	aurConfig := testConfig(t, "{test: {baseUrl: 'http://localhost:8000/'}}")
+
+	result := newAURProvider(aurConfig, testConfig(t, "{test: {follow: {aur: foo-bin}}}"), testHTTPClient)
+	assert.Equal(t, &aurProvider{pkgname: "foo-bin", baseURL: "http://localhost:8000", httpClient: testHTTPClient}, result)
+
+	result = newAURProvider(aurConfig, testConfig(t, "{test: {follow: {repo: extra/foo}}}"), testHTTPClient)
+	assert.Nil(t, result)
+}
+
+func TestAURLatestVersion(t *testing.T) {
+	cases := map[string]Version{
+		"1.2.3-1":   "1.2.3",
+		"2:1.2.3-4": "1.2.3",
+		"1.2.3-1.1": "1.2.3",
+	}
+
+	for fullVersion, expectedVersion := range cases {
+		gock.New("https://aur.archlinux.org").
+			Get("/rpc/v5/info").
+			MatchParam("arg[]", "foo").
+			Reply(200).
+			JSON(map[string]interface{}{
+				"resultcount": 1,
+				"results":     []map[string]string{{"Name": "foo", "Version": fullVersion}},
+			})
+
+		aur := aurProvider{pkgname: "foo", baseURL: defaultAURBaseURL, httpClient: testHTTPClient}
+
+		result, err := aur.LatestVersion()
+
+		assert.NoError(t, err)
+		assert.Equal(t, expectedVersion, result)
+		gock.Off()
+	}
+}
+
+func TestAURLatestVersion_NotFound(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://aur.archlinux.org").
+		Get("/rpc/v5/info").
+		Reply(200).
+		JSON(map[string]interface{}{"resultcount": 0, "results": []interface{}{}})
+
+	aur := aurProvider{pkgname: "foo", baseURL: defaultAURBaseURL, httpClient: testHTTPClient}
+
+	_, err := aur.LatestVersion()
+
+	assert.ErrorIs(t, err, ErrVersionNotFound)
+}
+
+func TestAUREqual(t *testing.T) {
+	aur := &aurProvider{pkgname: "foo"}
+
+	assert.True(t, aur.Equal(&aurProvider{pkgname: "foo"}))
+	assert.False(t, aur.Equal(&aurProvider{pkgname: "bar"}))
+	assert.False(t, aur.Equal(&archRepoProvider{pkgname: "foo"}))
+}
@@ -3,7 +3,9 @@ package upstream
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"regexp"
+	"strings"
 
 	"go.uber.org/config"
 )
@@ -12,30 +14,49 @@ var gitHubURLRegex = regexp.MustCompile(`github\.com/([^/#?]+)/([^/#?]+)`)
 
 // gitHubProvider tries to find the latest version both in releases and tags of a GitHub repo.
 type gitHubProvider struct {
-	owner  string
-	repo   string
-	apiKey string
+	rawVersionRecorder
+	owner      string
+	repo       string
+	apiKey     string
+	useGraphQL bool
+	prefetched *gitHubPrefetched
+	// latestTag is the tag of the version found by LatestVersion
+	latestTag string
+	// latestReleaseNotes is the body of the release found by LatestVersion
+	latestReleaseNotes string
+	filter             versionFilter
+	httpClient         *HTTPClient
 }
 
 type gitHubReleaseResp struct {
-	Name       string `json:"name"`
-	TagName    string `json:"tag_name"`
-	Prerelease bool   `json:"prerelease"`
-	Draft      bool   `json:"draft"`
+	Name       string               `json:"name"`
+	TagName    string               `json:"tag_name"`
+	Prerelease bool                 `json:"prerelease"`
+	Draft      bool                 `json:"draft"`
+	Body       string               `json:"body"`
+	Assets     []gitHubReleaseAsset `json:"assets"`
+}
+
+type gitHubReleaseAsset struct {
+	Name string `json:"name"`
 }
 
 type gitHubTagResp struct {
 	Name string `json:"name"`
 }
 
-func newGitHubProvider(url string, gitHubConfig config.Value) *gitHubProvider {
+func newGitHubProvider(url string, gitHubConfig config.Value, filter versionFilter, httpClient *HTTPClient) *gitHubProvider {
 	match := gitHubURLRegex.FindStringSubmatch(url)
 	if len(match) == 0 {
 		return nil
 	}
 
-	provider := gitHubProvider{owner: match[1], repo: match[2]}
+	provider := gitHubProvider{owner: match[1], repo: match[2], filter: filter, httpClient: httpClient}
 	gitHubConfig.Get("apiKey").Populate(&provider.apiKey) //nolint:errcheck
+	// GraphQL API is not available without authentication
+	if provider.apiKey != "" {
+		gitHubConfig.Get("graphQL").Populate(&provider.useGraphQL) //nolint:errcheck
+	}
 
 	return &provider
 }
@@ -58,12 +79,17 @@ func (gitHub *gitHubProvider) apiHeaders() map[string]string {
 }
 
 func (gitHub *gitHubProvider) LatestVersion() (Version, error) {
+	if gitHub.prefetched != nil {
+		return gitHub.prefetchedVersion()
+	}
+
 	latestReleaseVersion, releaseErr := gitHub.latestReleaseVersion()
 	if releaseErr == nil {
 		return latestReleaseVersion, nil
 	}
 
-	if !errors.Is(releaseErr, ErrVersionNotFound) {
+	// Tags have no assets, so there's no point in checking them if asset is required
+	if !errors.Is(releaseErr, ErrVersionNotFound) || gitHub.filter.requiresAsset() {
 		return "", releaseErr
 	}
 
@@ -77,18 +103,29 @@ func (gitHub *gitHubProvider) LatestVersion() (Version, error) {
 
 func (gitHub *gitHubProvider) latestReleaseVersion() (Version, error) {
 	var latestReleases []gitHubReleaseResp
-	if err := httpGetJSON(gitHub.releasesURL(), &latestReleases, gitHub.apiHeaders()); err != nil {
+	if err := gitHub.httpClient.getJSON(gitHub.releasesURL(), &latestReleases, gitHub.apiHeaders()); err != nil {
 		return "", err
 	}
 
+	return gitHub.releasesVersion(latestReleases)
+}
+
+// releasesVersion returns version of the first valid release from the list.
+func (gitHub *gitHubProvider) releasesVersion(latestReleases []gitHubReleaseResp) (Version, error) {
 	for _, release := range latestReleases {
-		if release.Draft || release.Prerelease {
+		if release.Draft || release.Prerelease || !gitHub.filter.hasRequiredAsset(release.assetNames()) {
 			continue
 		}
-		if version, isValid := ParseVersion(release.TagName); isValid {
+		if version, rawVersion, isValid := gitHub.filter.parseTag(release.TagName); isValid {
+			gitHub.latestRawVersion = rawVersion
+			gitHub.latestTag = release.TagName
+			gitHub.latestReleaseNotes = release.Body
 			return version, nil
 		}
-		if version, isValid := ParseVersion(release.Name); isValid {
+		if version, rawVersion, isValid := gitHub.filter.parseTag(release.Name); isValid {
+			gitHub.latestRawVersion = rawVersion
+			gitHub.latestTag = release.TagName
+			gitHub.latestReleaseNotes = release.Body
 			return version, nil
 		}
 	}
@@ -96,18 +133,34 @@ func (gitHub *gitHubProvider) latestReleaseVersion() (Version, error) {
 	return "", ErrVersionNotFound
 }
 
+func (release *gitHubReleaseResp) assetNames() []string {
+	assetNames := make([]string, 0, len(release.Assets))
+	for _, asset := range release.Assets {
+		assetNames = append(assetNames, asset.Name)
+	}
+	return assetNames
+}
+
 func (gitHub *gitHubProvider) releasesURL() string {
 	return fmt.Sprintf("https://api.github.com/repos/%s/%s/releases", gitHub.owner, gitHub.repo)
 }
 
 func (gitHub *gitHubProvider) latestTagVersion() (Version, error) {
 	var latestTags []gitHubTagResp
-	if err := httpGetJSON(gitHub.tagsURL(), &latestTags, gitHub.apiHeaders()); err != nil {
+	if err := gitHub.httpClient.getJSON(gitHub.tagsURL(), &latestTags, gitHub.apiHeaders()); err != nil {
 		return "", err
 	}
 
+	return gitHub.tagsVersion(latestTags)
+}
+
+// tagsVersion returns version of the first valid tag from the list.
+func (gitHub *gitHubProvider) tagsVersion(latestTags []gitHubTagResp) (Version, error) {
 	for _, tag := range latestTags {
-		if version, isValid := ParseVersion(tag.Name); isValid {
+		if version, rawVersion, isValid := gitHub.filter.parseTag(tag.Name); isValid {
+			gitHub.latestRawVersion = rawVersion
+			gitHub.latestTag = tag.Name
+			gitHub.latestReleaseNotes = ""
 			return version, nil
 		}
 	}
@@ -118,3 +171,39 @@ func (gitHub *gitHubProvider) latestTagVersion() (Version, error) {
 func (gitHub *gitHubProvider) tagsURL() string {
 	return fmt.Sprintf("https://api.github.com/repos/%s/%s/tags", gitHub.owner, gitHub.repo)
 }
+
+// LatestRevision returns the latest version tag and the commit it points at.
+// The commit is not included in releases nor tags list, so it requires another request.
+func (gitHub *gitHubProvider) LatestRevision() (Revision, error) {
+	if gitHub.latestTag == "" {
+		return Revision{}, ErrVersionNotFound
+	}
+
+	headers := gitHub.apiHeaders()
+	headers["Accept"] = "application/vnd.github.sha"
+	commitURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/commits/%s", gitHub.owner, gitHub.repo, url.PathEscape(gitHub.latestTag))
+	commit, err := gitHub.httpClient.getText(commitURL, headers)
+	if err != nil {
+		return Revision{}, err
+	}
+
+	return Revision{Tag: gitHub.latestTag, Commit: strings.TrimSpace(commit)}, nil
+}
+
+func (gitHub *gitHubProvider) LatestReleaseNotes() string {
+	return gitHub.latestReleaseNotes
+}
+
+type gitHubCompareResp struct {
+	AheadBy int `json:"ahead_by"`
+}
+
+// commitsBehind returns the number of commits between base and head.
+func (gitHub *gitHubProvider) commitsBehind(base string, head string) (int, error) {
+	var compare gitHubCompareResp
+	compareURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/compare/%s...%s", gitHub.owner, gitHub.repo, base, head)
+	if err := gitHub.httpClient.getJSON(compareURL, &compare, gitHub.apiHeaders()); err != nil {
+		return 0, err
+	}
+	return compare.AheadBy, nil
+}
@@ -0,0 +1,180 @@
+package upstream
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	gitHubGraphQLURL = "https://api.github.com/graphql"
+	// gitHubGraphQLBatchSize is the number of repositories queried in a single GraphQL request.
+	gitHubGraphQLBatchSize = 50
+	// gitHubGraphQLItemsCount is the number of the latest releases and tags fetched for each repository.
+	gitHubGraphQLItemsCount = 20
+)
+
+const gitHubGraphQLFragment = `
+fragment repoVersions on Repository {
+  releases(first: %[1]d, orderBy: {field: CREATED_AT, direction: DESC}) {
+    nodes {
+      name tagName isPrerelease isDraft description
+      releaseAssets(first: 100) { nodes { name } }
+    }
+  }
+  refs(refPrefix: "refs/tags/", first: %[1]d, orderBy: {field: TAG_COMMIT_DATE, direction: DESC}) {
+    nodes { name }
+  }
+}`
+
+// gitHubPrefetched holds releases and tags of a GitHub repo fetched in advance using the GraphQL API.
+type gitHubPrefetched struct {
+	releases []gitHubReleaseResp
+	tags     []gitHubTagResp
+}
+
+type gitHubGraphQLReq struct {
+	Query string `json:"query"`
+}
+
+type gitHubGraphQLResp struct {
+	Data   map[string]*gitHubGraphQLRepoResp `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+type gitHubGraphQLRepoResp struct {
+	Releases struct {
+		Nodes []struct {
+			Name         string `json:"name"`
+			TagName      string `json:"tagName"`
+			IsPrerelease bool   `json:"isPrerelease"`
+			IsDraft      bool   `json:"isDraft"`
+			Description  string `json:"description"`
+			Assets       struct {
+				Nodes []gitHubReleaseAsset `json:"nodes"`
+			} `json:"releaseAssets"`
+		} `json:"nodes"`
+	} `json:"releases"`
+	Refs struct {
+		Nodes []gitHubTagResp `json:"nodes"`
+	} `json:"refs"`
+}
+
+func (repoResp *gitHubGraphQLRepoResp) toPrefetched() *gitHubPrefetched {
+	prefetched := gitHubPrefetched{
+		releases: make([]gitHubReleaseResp, 0, len(repoResp.Releases.Nodes)),
+		tags:     repoResp.Refs.Nodes,
+	}
+	for _, release := range repoResp.Releases.Nodes {
+		prefetched.releases = append(prefetched.releases, gitHubReleaseResp{
+			Name:       release.Name,
+			TagName:    release.TagName,
+			Prerelease: release.IsPrerelease,
+			Draft:      release.IsDraft,
+			Body:       release.Description,
+			Assets:     release.Assets.Nodes,
+		})
+	}
+	return &prefetched
+}
+
+// prefetchedVersion finds the latest version in prefetched releases, falling back to prefetched tags.
+func (gitHub *gitHubProvider) prefetchedVersion() (Version, error) {
+	version, err := gitHub.releasesVersion(gitHub.prefetched.releases)
+	if err == nil || gitHub.filter.requiresAsset() {
+		return version, err
+	}
+	return gitHub.tagsVersion(gitHub.prefetched.tags)
+}
+
+func (gitHub *gitHubProvider) repoKey() string {
+	return gitHub.owner + "/" + gitHub.repo
+}
+
+// prefetchGitHub fetches releases and tags of all the given GitHub providers' repos in batched GraphQL queries.
+// Only providers with GraphQL enabled are considered. Repos which couldn't be fetched are left as they were,
+// so their providers will fall back to the REST API.
+func prefetchGitHub(providers []VersionProvider) error {
+	reposProviders := map[string][]*gitHubProvider{}
+	repoKeys := []string{}
+	for _, provider := range providers {
+		gitHub, isGitHub := provider.(*gitHubProvider)
+		if !isGitHub || !gitHub.useGraphQL {
+			continue
+		}
+		if _, isKnown := reposProviders[gitHub.repoKey()]; !isKnown {
+			repoKeys = append(repoKeys, gitHub.repoKey())
+		}
+		reposProviders[gitHub.repoKey()] = append(reposProviders[gitHub.repoKey()], gitHub)
+	}
+
+	batchErrs := []error{}
+	for batchStart := 0; batchStart < len(repoKeys); batchStart += gitHubGraphQLBatchSize {
+		batchEnd := min(batchStart+gitHubGraphQLBatchSize, len(repoKeys))
+		batch := make([]*gitHubProvider, 0, batchEnd-batchStart)
+		for _, repoKey := range repoKeys[batchStart:batchEnd] {
+			batch = append(batch, reposProviders[repoKey][0])
+		}
+
+		reposPrefetched, err := queryGitHubGraphQL(batch)
+		if err != nil {
+			batchErrs = append(batchErrs, err)
+		}
+		for repoKey, prefetched := range reposPrefetched {
+			for _, gitHub := range reposProviders[repoKey] {
+				gitHub.prefetched = prefetched
+			}
+		}
+	}
+
+	return errors.Join(batchErrs...)
+}
+
+// queryGitHubGraphQL sends a single GraphQL query for all the given repos.
+// Returns prefetched data for each successfully queried repo, keyed by repoKey.
+func queryGitHubGraphQL(batch []*gitHubProvider) (map[string]*gitHubPrefetched, error) {
+	var resp gitHubGraphQLResp
+	err := batch[0].httpClient.postJSON(gitHubGraphQLURL, gitHubGraphQLReq{Query: gitHubGraphQLQuery(batch)}, &resp, batch[0].apiHeaders())
+	if err != nil {
+		return nil, err
+	}
+
+	reposPrefetched := map[string]*gitHubPrefetched{}
+	for i, gitHub := range batch {
+		if repoResp := resp.Data[gitHubGraphQLAlias(i)]; repoResp != nil {
+			reposPrefetched[gitHub.repoKey()] = repoResp.toPrefetched()
+		}
+	}
+
+	if len(resp.Errors) != 0 {
+		messages := make([]string, 0, len(resp.Errors))
+		for _, respErr := range resp.Errors {
+			messages = append(messages, respErr.Message)
+		}
+		return reposPrefetched, fmt.Errorf("%w: GraphQL errors: %s", ErrProviderError, strings.Join(messages, "; "))
+	}
+
+	return reposPrefetched, nil
+}
+
+// gitHubGraphQLQuery builds query fetching versions of all the given repos, each repo under its own alias.
+func gitHubGraphQLQuery(batch []*gitHubProvider) string {
+	query := strings.Builder{}
+	query.WriteString("query {\n")
+	for i, gitHub := range batch {
+		// JSON string literals are valid GraphQL string literals, this takes care of escaping
+		owner, _ := json.Marshal(gitHub.owner)
+		repo, _ := json.Marshal(gitHub.repo)
+		fmt.Fprintf(&query, "  %s: repository(owner: %s, name: %s) { ...repoVersions }\n", gitHubGraphQLAlias(i), owner, repo)
+	}
+	query.WriteString("}\n")
+	fmt.Fprintf(&query, gitHubGraphQLFragment, gitHubGraphQLItemsCount)
+	return query.String()
+}
+
+func gitHubGraphQLAlias(index int) string {
+	return fmt.Sprintf("repo%d", index)
+}
@@ -0,0 +1,157 @@
+package upstream
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/config"
+)
+
+var (
+	gitHubGraphQLConfigProvider, _ = config.NewYAML(config.Source(strings.NewReader(
+		"{noKey: {graphQL: true}, github: {apiKey: test_api_key, graphQL: true}}",
+	)))
+	gitHubGraphQLNoKeyConfig = gitHubGraphQLConfigProvider.Get("noKey")
+	gitHubGraphQLConfig      = gitHubGraphQLConfigProvider.Get("github")
+)
+
+// graphQLQueryMatcher matches GraphQL requests which query given repos, in given order.
+func graphQLQueryMatcher(repos ...string) gock.MatchFunc {
+	return func(request *http.Request, _requestMock *gock.Request) (bool, error) {
+		body, err := io.ReadAll(request.Body)
+		if err != nil {
+			return false, err
+		}
+		var graphQLReq gitHubGraphQLReq
+		if err := json.Unmarshal(body, &graphQLReq); err != nil {
+			return false, err
+		}
+		for i, repo := range repos {
+			owner, name, _ := strings.Cut(repo, "/")
+			expectedLine := gitHubGraphQLAlias(i) + `: repository(owner: "` + owner + `", name: "` + name + `")`
+			if !strings.Contains(graphQLReq.Query, expectedLine) {
+				return false, nil
+			}
+		}
+		return strings.Count(graphQLReq.Query, "repository(") == len(repos), nil
+	}
+}
+
+func TestNewGithub_GraphQL(t *testing.T) {
+	validURL := "https://github.com/bcyran/timewall"
+
+	assert.True(t, newGitHubProvider(validURL, gitHubGraphQLConfig, versionFilter{}, testHTTPClient).useGraphQL)
+	// no API key, no GraphQL
+	assert.False(t, newGitHubProvider(validURL, gitHubGraphQLNoKeyConfig, versionFilter{}, testHTTPClient).useGraphQL)
+}
+
+func TestPrefetchVersions_GitHub(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.github.com").
+		Post("/graphql").
+		MatchHeader("Authorization", "Bearer test_token").
+		AddMatcher(graphQLQueryMatcher("foo/bar", "foo/baz", "foo/missing")).
+		Reply(200).
+		JSON(map[string]interface{}{
+			"data": map[string]interface{}{
+				"repo0": map[string]interface{}{
+					"releases": map[string]interface{}{
+						"nodes": []map[string]interface{}{
+							{"name": "Draft", "tagName": "2.0.0", "isPrerelease": false, "isDraft": true},
+							{"name": "Foo", "tagName": "v1.6.9", "isPrerelease": false, "isDraft": false, "description": "Notes"},
+						},
+					},
+					"refs": map[string]interface{}{"nodes": []map[string]string{{"name": "v1.6.9"}}},
+				},
+				"repo1": map[string]interface{}{
+					"releases": map[string]interface{}{"nodes": []interface{}{}},
+					"refs":     map[string]interface{}{"nodes": []map[string]string{{"name": "nope"}, {"name": "0.4.2"}}},
+				},
+				"repo2": nil,
+			},
+			"errors": []map[string]string{
+				{"message": "Could not resolve to a Repository with the name 'foo/missing'."},
+			},
+		})
+
+	fooBar := &gitHubProvider{owner: "foo", repo: "bar", apiKey: "test_token", useGraphQL: true, httpClient: testHTTPClient}
+	fooBarDuplicate := &gitHubProvider{owner: "foo", repo: "bar", apiKey: "test_token", useGraphQL: true, httpClient: testHTTPClient}
+	fooBaz := &gitHubProvider{owner: "foo", repo: "baz", apiKey: "test_token", useGraphQL: true, httpClient: testHTTPClient}
+	fooMissing := &gitHubProvider{owner: "foo", repo: "missing", apiKey: "test_token", useGraphQL: true, httpClient: testHTTPClient}
+	restOnly := &gitHubProvider{owner: "foo", repo: "rest", httpClient: testHTTPClient}
+
+	err := PrefetchVersions([]VersionProvider{fooBar, restOnly, fooBaz, fooBarDuplicate, fooMissing, &pypiProvider{}})
+
+	assert.ErrorIs(t, err, ErrProviderError)
+	assert.ErrorContains(t, err, "Could not resolve to a Repository")
+	assert.True(t, gock.IsDone())
+	assert.Nil(t, restOnly.prefetched)
+	assert.Nil(t, fooMissing.prefetched)
+
+	// no more requests are made, all the versions are prefetched
+	for provider, expectedVersion := range map[*gitHubProvider]Version{fooBar: "1.6.9", fooBarDuplicate: "1.6.9", fooBaz: "0.4.2"} {
+		version, err := provider.LatestVersion()
+		assert.NoError(t, err)
+		assert.Equal(t, expectedVersion, version)
+	}
+	assert.Equal(t, "Notes", fooBar.LatestReleaseNotes())
+	assert.Equal(t, "", fooBaz.LatestReleaseNotes())
+}
+
+func TestPrefetchVersions_GitHubBatches(t *testing.T) {
+	providers := []VersionProvider{}
+	repos := []string{}
+	for i := 0; i < gitHubGraphQLBatchSize+1; i++ {
+		repo := "repo" + strings.Repeat("x", i)
+		repos = append(repos, "foo/"+repo)
+		providers = append(providers, &gitHubProvider{owner: "foo", repo: repo, useGraphQL: true, httpClient: testHTTPClient})
+	}
+
+	defer gock.Off()
+	gock.New("https://api.github.com").
+		Post("/graphql").
+		AddMatcher(graphQLQueryMatcher(repos[:gitHubGraphQLBatchSize]...)).
+		Reply(200).
+		JSON(map[string]interface{}{"data": map[string]interface{}{}})
+	gock.New("https://api.github.com").
+		Post("/graphql").
+		AddMatcher(graphQLQueryMatcher(repos[gitHubGraphQLBatchSize:]...)).
+		Reply(200).
+		JSON(map[string]interface{}{"data": map[string]interface{}{}})
+
+	err := PrefetchVersions(providers)
+
+	assert.NoError(t, err)
+	assert.True(t, gock.IsDone())
+}
+
+func TestPrefetchVersions_GitHubRequestFailed(t *testing.T) {
+	httpClient, _ := newFakeClockHTTPClient(t)
+	defer gock.Off()
+	gock.New("https://api.github.com").
+		Post("/graphql").
+		Reply(401).
+		JSON(map[string]string{"message": "Bad credentials"})
+	gock.New("https://api.github.com").
+		Get("/repos/foo/bar/releases").
+		Reply(200).
+		JSON([]map[string]interface{}{
+			{"name": "Foo", "tag_name": "1.2.3", "prerelease": false, "draft": false},
+		})
+
+	gitHub := &gitHubProvider{owner: "foo", repo: "bar", useGraphQL: true, httpClient: httpClient}
+
+	err := PrefetchVersions([]VersionProvider{gitHub})
+	assert.Error(t, err)
+	assert.Nil(t, gitHub.prefetched)
+
+	// falls back to the REST API
+	version, err := gitHub.LatestVersion()
+	assert.NoError(t, err)
+	assert.Equal(t, Version("1.2.3"), version)
+}
@@ -1,6 +1,7 @@
 package upstream
 
 import (
+	"regexp"
 	"strings"
 	"testing"
 
@@ -21,11 +22,12 @@ var (
 func TestNewGithub_Valid(t *testing.T) {
 	validURL := "https://github.com/bcyran/timewall?foo=bar#whatever"
 	expectedResult := gitHubProvider{
-		owner: "bcyran",
-		repo:  "timewall",
+		owner:      "bcyran",
+		repo:       "timewall",
+		httpClient: testHTTPClient,
 	}
 
-	result := newGitHubProvider(validURL, gitHubEmptyConfig)
+	result := newGitHubProvider(validURL, gitHubEmptyConfig, versionFilter{}, testHTTPClient)
 
 	assert.Equal(t, &expectedResult, result)
 }
@@ -33,12 +35,13 @@ func TestNewGithub_Valid(t *testing.T) {
 func TestNewGithub_ValidWithApiKey(t *testing.T) {
 	validURL := "https://github.com/bcyran/timewall?foo=bar#whatever"
 	expectedResult := gitHubProvider{
-		owner:  "bcyran",
-		repo:   "timewall",
-		apiKey: "test_api_key",
+		owner:      "bcyran",
+		repo:       "timewall",
+		apiKey:     "test_api_key",
+		httpClient: testHTTPClient,
 	}
 
-	result := newGitHubProvider(validURL, gitHubAPIKeyConfig)
+	result := newGitHubProvider(validURL, gitHubAPIKeyConfig, versionFilter{}, testHTTPClient)
 
 	assert.Equal(t, &expectedResult, result)
 }
@@ -46,7 +49,7 @@ func TestNewGithub_ValidWithApiKey(t *testing.T) {
 func TestNewGithub_Invalid(t *testing.T) {
 	invalidURL := "https://github.com/randompath"
 
-	result := newGitHubProvider(invalidURL, gitHubEmptyConfig)
+	result := newGitHubProvider(invalidURL, gitHubEmptyConfig, versionFilter{}, testHTTPClient)
 
 	assert.Nil(t, result)
 }
@@ -84,7 +87,7 @@ func TestGithubLatestVersion_Release(t *testing.T) {
 			},
 		})
 
-	gitHub := gitHubProvider{owner: "foo", repo: "bar"}
+	gitHub := gitHubProvider{owner: "foo", repo: "bar", httpClient: testHTTPClient}
 
 	result, err := gitHub.LatestVersion()
 
@@ -107,7 +110,7 @@ func TestGithubLatestVersion_ReleaseWithApiKey(t *testing.T) {
 			},
 		})
 
-	gitHub := gitHubProvider{owner: "foo", repo: "bar", apiKey: "test_token"}
+	gitHub := gitHubProvider{owner: "foo", repo: "bar", apiKey: "test_token", httpClient: testHTTPClient}
 
 	result, err := gitHub.LatestVersion()
 
@@ -132,7 +135,7 @@ func TestGithubLatestVersion_Tag(t *testing.T) {
 			{"name": "1.6.8"},
 		})
 
-	gitHub := gitHubProvider{owner: "foo", repo: "bar"}
+	gitHub := gitHubProvider{owner: "foo", repo: "bar", httpClient: testHTTPClient}
 
 	result, err := gitHub.LatestVersion()
 
@@ -155,7 +158,7 @@ func TestGithubLatestVersion_TagWithApiKey(t *testing.T) {
 			{"name": "1.6.9"},
 		})
 
-	gitHub := gitHubProvider{owner: "foo", repo: "bar", apiKey: "test_token"}
+	gitHub := gitHubProvider{owner: "foo", repo: "bar", apiKey: "test_token", httpClient: testHTTPClient}
 
 	result, err := gitHub.LatestVersion()
 
@@ -174,7 +177,7 @@ func TestGithubLatestVersion_NoVersions(t *testing.T) {
 		Reply(200).
 		JSON([]interface{}{})
 
-	gitHub := gitHubProvider{owner: "foo", repo: "bar"}
+	gitHub := gitHubProvider{owner: "foo", repo: "bar", httpClient: testHTTPClient}
 
 	_, err := gitHub.LatestVersion()
 
@@ -192,7 +195,7 @@ func TestGithubLatestVersion_4xx(t *testing.T) {
 		Reply(401).
 		JSON([]interface{}{})
 
-	gitHub := gitHubProvider{owner: "foo", repo: "bar"}
+	gitHub := gitHubProvider{owner: "foo", repo: "bar", httpClient: testHTTPClient}
 
 	_, err := gitHub.LatestVersion()
 
@@ -200,19 +203,132 @@ func TestGithubLatestVersion_4xx(t *testing.T) {
 }
 
 func TestGithubLatestVersion_5xx(t *testing.T) {
+	httpClient, _ := newFakeClockHTTPClient(t)
 	defer gock.Off()
 	gock.New("https://api.github.com").
 		Get("/repos/foo/bar/releases").
+		Times(maxRetries + 1).
 		Reply(500).
 		JSON([]interface{}{})
 	gock.New("https://api.github.com").
 		Get("/repos/foo/bar/tags").
+		Times(maxRetries + 1).
 		Reply(501).
 		JSON([]interface{}{})
 
-	gitHub := gitHubProvider{owner: "foo", repo: "bar"}
+	gitHub := gitHubProvider{owner: "foo", repo: "bar", httpClient: httpClient}
 
 	_, err := gitHub.LatestVersion()
 
 	assert.ErrorIs(t, err, ErrProviderError)
 }
+
+func TestGithubLatestVersion_TagPatternAndAsset(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.github.com").
+		Get("/repos/foo/bar/releases").
+		Reply(200).
+		JSON([]map[string]interface{}{
+			{"name": "SDK", "tag_name": "sdk-v4.0.0", "assets": []map[string]string{{"name": "sdk-linux-x86_64.tar.gz"}}},
+			{"name": "CLI without binaries", "tag_name": "cli-v1.3.0", "assets": []map[string]string{{"name": "cli-darwin.tar.gz"}}},
+			{"name": "CLI", "tag_name": "cli-v1.2.3", "assets": []map[string]string{{"name": "cli-linux-x86_64.tar.gz"}}},
+		})
+
+	gitHub := gitHubProvider{
+		owner:      "foo",
+		repo:       "bar",
+		filter:     versionFilter{tagPattern: regexp.MustCompile(`^cli-v(?P<version>.+)$`), assetPattern: "*-linux-x86_64.tar.gz"},
+		httpClient: testHTTPClient,
+	}
+
+	result, err := gitHub.LatestVersion()
+
+	assert.NoError(t, err)
+	assert.Equal(t, Version("1.2.3"), result)
+}
+
+func TestGithubLatestVersion_TagPatternTag(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.github.com").
+		Get("/repos/foo/bar/releases").
+		Reply(200).
+		JSON([]interface{}{})
+	gock.New("https://api.github.com").
+		Get("/repos/foo/bar/tags").
+		Reply(200).
+		JSON([]map[string]interface{}{
+			{"name": "sdk-v4.0.0"},
+			{"name": "cli-v1.2.3"},
+		})
+
+	gitHub := gitHubProvider{
+		owner:      "foo",
+		repo:       "bar",
+		filter:     versionFilter{tagPattern: regexp.MustCompile(`^cli-v(?P<version>.+)$`)},
+		httpClient: testHTTPClient,
+	}
+
+	result, err := gitHub.LatestVersion()
+
+	assert.NoError(t, err)
+	assert.Equal(t, Version("1.2.3"), result)
+}
+
+func TestGithubLatestVersion_AssetRequiredNoTagFallback(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.github.com").
+		Get("/repos/foo/bar/releases").
+		Reply(200).
+		JSON([]map[string]interface{}{
+			{"name": "Foo", "tag_name": "1.2.3", "assets": []interface{}{}},
+		})
+
+	gitHub := gitHubProvider{owner: "foo", repo: "bar", filter: versionFilter{assetPattern: "*.tar.gz"}, httpClient: testHTTPClient}
+
+	_, err := gitHub.LatestVersion()
+
+	assert.ErrorIs(t, err, ErrVersionNotFound)
+	// tags were not requested
+	assert.True(t, gock.IsDone())
+}
+
+func TestGithubLatestRevision(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.github.com").
+		Get("/repos/foo/bar/releases").
+		Reply(200).
+		JSON([]map[string]interface{}{{"name": "Foo", "tag_name": "v1.6.9"}})
+	gock.New("https://api.github.com").
+		Get("/repos/foo/bar/commits/v1.6.9").
+		MatchHeader("Accept", "application/vnd.github.sha").
+		Reply(200).
+		BodyString("0123456789abcdef0123456789abcdef01234567")
+
+	gitHub := gitHubProvider{owner: "foo", repo: "bar", httpClient: testHTTPClient}
+
+	_, err := gitHub.LatestRevision()
+	assert.ErrorIs(t, err, ErrVersionNotFound)
+
+	_, err = gitHub.LatestVersion()
+	assert.NoError(t, err)
+	result, err := gitHub.LatestRevision()
+
+	assert.NoError(t, err)
+	assert.Equal(t, Revision{Tag: "v1.6.9", Commit: "0123456789abcdef0123456789abcdef01234567"}, result)
+	assert.True(t, gock.IsDone())
+}
+
+func TestGithubLatestReleaseNotes(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.github.com").
+		Get("/repos/foo/bar/releases").
+		Reply(200).
+		JSON([]map[string]interface{}{{"name": "Foo", "tag_name": "v1.6.9", "body": "Fixed everything"}})
+
+	gitHub := gitHubProvider{owner: "foo", repo: "bar", httpClient: testHTTPClient}
+
+	_, err := gitHub.LatestVersion()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Fixed everything", gitHub.LatestReleaseNotes())
+}
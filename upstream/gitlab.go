@@ -15,29 +15,45 @@ var gitLabURLRegex = regexp.MustCompile(`([^/#?]*git[^/#?]*)/([^/#?]+)/([^#?]+?)
 
 // gitLabProvider tries to find the latest version both in releases and tags of a gitLab repo.
 type gitLabProvider struct {
+	rawVersionRecorder
 	netloc string
 	owner  string
 	repo   string
 	apiKey string
+	// latestTag is the tag of the version found by LatestVersion
+	latestTag string
+	// latestReleaseNotes is the description of the release found by LatestVersion
+	latestReleaseNotes string
+	filter             versionFilter
+	httpClient         *HTTPClient
 }
 
 type gitLabReleaseResp struct {
-	Name     string `json:"name"`
-	TagName  string `json:"tag_name"`
-	Upcoming bool   `json:"upcoming_release"`
+	Name        string `json:"name"`
+	TagName     string `json:"tag_name"`
+	Upcoming    bool   `json:"upcoming_release"`
+	Description string `json:"description"`
+	Assets      struct {
+		Links []struct {
+			Name string `json:"name"`
+		} `json:"links"`
+	} `json:"assets"`
 }
 
 type gitLabTagResp struct {
-	Name string `json:"name"`
+	Name   string `json:"name"`
+	Commit struct {
+		ID string `json:"id"`
+	} `json:"commit"`
 }
 
-func newGitLabProvider(url string, gitLabConfig config.Value) *gitLabProvider {
+func newGitLabProvider(url string, gitLabConfig config.Value, filter versionFilter, httpClient *HTTPClient) *gitLabProvider {
 	match := gitLabURLRegex.FindStringSubmatch(url)
 	if len(match) == 0 {
 		return nil
 	}
 
-	provider := gitLabProvider{netloc: match[1], owner: match[2], repo: match[3]}
+	provider := gitLabProvider{netloc: match[1], owner: match[2], repo: match[3], filter: filter, httpClient: httpClient}
 	// config.Value.Get(path string) doesn't work when path contains dots, like URLs
 	apiKeysMap := map[string]string{}
 	gitLabConfig.Get("apiKeys").Populate(&apiKeysMap) //nolint:errcheck
@@ -79,7 +95,8 @@ func (gitLab *gitLabProvider) LatestVersion() (Version, error) {
 		return latestReleaseVersion, nil
 	}
 
-	if !errors.Is(releaseErr, ErrVersionNotFound) {
+	// Tags have no assets, so there's no point in checking them if asset is required
+	if !errors.Is(releaseErr, ErrVersionNotFound) || gitLab.filter.requiresAsset() {
 		return "", releaseErr
 	}
 
@@ -91,24 +108,38 @@ func (gitLab *gitLabProvider) LatestVersion() (Version, error) {
 	return latestTagVersion, nil
 }
 
+func (release *gitLabReleaseResp) assetNames() []string {
+	assetNames := make([]string, 0, len(release.Assets.Links))
+	for _, link := range release.Assets.Links {
+		assetNames = append(assetNames, link.Name)
+	}
+	return assetNames
+}
+
 func (gitLab *gitLabProvider) releasesURL() string {
 	return fmt.Sprintf("%s/projects/%s/releases", gitLab.apiURL(), gitLab.projectID())
 }
 
 func (gitLab *gitLabProvider) latestReleaseVersion() (Version, error) {
 	var latestReleases []gitLabReleaseResp
-	if err := httpGetJSON(gitLab.releasesURL(), &latestReleases, gitLab.apiHeaders()); err != nil {
+	if err := gitLab.httpClient.getJSON(gitLab.releasesURL(), &latestReleases, gitLab.apiHeaders()); err != nil {
 		return "", err
 	}
 
 	for _, release := range latestReleases {
-		if release.Upcoming {
+		if release.Upcoming || !gitLab.filter.hasRequiredAsset(release.assetNames()) {
 			continue
 		}
-		if version, isValid := ParseVersion(release.TagName); isValid {
+		if version, rawVersion, isValid := gitLab.filter.parseTag(release.TagName); isValid {
+			gitLab.latestRawVersion = rawVersion
+			gitLab.latestTag = release.TagName
+			gitLab.latestReleaseNotes = release.Description
 			return version, nil
 		}
-		if version, isValid := ParseVersion(release.Name); isValid {
+		if version, rawVersion, isValid := gitLab.filter.parseTag(release.Name); isValid {
+			gitLab.latestRawVersion = rawVersion
+			gitLab.latestTag = release.TagName
+			gitLab.latestReleaseNotes = release.Description
 			return version, nil
 		}
 	}
@@ -122,15 +153,53 @@ func (gitLab *gitLabProvider) tagsURL() string {
 
 func (gitLab *gitLabProvider) latestTagVersion() (Version, error) {
 	var latestTags []gitLabTagResp
-	if err := httpGetJSON(gitLab.tagsURL(), &latestTags, gitLab.apiHeaders()); err != nil {
+	if err := gitLab.httpClient.getJSON(gitLab.tagsURL(), &latestTags, gitLab.apiHeaders()); err != nil {
 		return "", err
 	}
 
 	for _, tag := range latestTags {
-		if version, isValid := ParseVersion(tag.Name); isValid {
+		if version, rawVersion, isValid := gitLab.filter.parseTag(tag.Name); isValid {
+			gitLab.latestRawVersion = rawVersion
+			gitLab.latestTag = tag.Name
+			gitLab.latestReleaseNotes = ""
 			return version, nil
 		}
 	}
 
 	return "", ErrVersionNotFound
 }
+
+// LatestRevision returns the latest version tag and the commit it points at.
+func (gitLab *gitLabProvider) LatestRevision() (Revision, error) {
+	if gitLab.latestTag == "" {
+		return Revision{}, ErrVersionNotFound
+	}
+
+	var tag gitLabTagResp
+	tagURL := fmt.Sprintf("%s/%s", gitLab.tagsURL(), url.PathEscape(gitLab.latestTag))
+	if err := gitLab.httpClient.getJSON(tagURL, &tag, gitLab.apiHeaders()); err != nil {
+		return Revision{}, err
+	}
+
+	return Revision{Tag: gitLab.latestTag, Commit: tag.Commit.ID}, nil
+}
+
+func (gitLab *gitLabProvider) LatestReleaseNotes() string {
+	return gitLab.latestReleaseNotes
+}
+
+type gitLabCompareResp struct {
+	Commits []struct {
+		ID string `json:"id"`
+	} `json:"commits"`
+}
+
+// commitsBehind returns the number of commits between base and head.
+func (gitLab *gitLabProvider) commitsBehind(base string, head string) (int, error) {
+	var compare gitLabCompareResp
+	compareURL := fmt.Sprintf("%s/projects/%s/repository/compare?from=%s&to=%s", gitLab.apiURL(), gitLab.projectID(), base, head)
+	if err := gitLab.httpClient.getJSON(compareURL, &compare, gitLab.apiHeaders()); err != nil {
+		return 0, err
+	}
+	return len(compare.Commits), nil
+}
@@ -1,6 +1,7 @@
 package upstream
 
 import (
+	"regexp"
 	"strings"
 	"testing"
 
@@ -54,7 +55,8 @@ func TestNewGitLab_Valid(t *testing.T) {
 	}
 
 	for validURL, expectedResult := range cases {
-		result := newGitLabProvider(validURL, gitLabAPIKeyConfig)
+		expectedResult.httpClient = testHTTPClient
+		result := newGitLabProvider(validURL, gitLabAPIKeyConfig, versionFilter{}, testHTTPClient)
 		assert.Equal(t, &expectedResult, result)
 	}
 }
@@ -66,7 +68,7 @@ func TestNewGitLab_Invalid(t *testing.T) {
 	}
 
 	for _, invalidURL := range invalidURLs {
-		result := newGitLabProvider(invalidURL, gitLabEmptyConfig)
+		result := newGitLabProvider(invalidURL, gitLabEmptyConfig, versionFilter{}, testHTTPClient)
 		assert.Nil(t, result)
 	}
 }
@@ -94,7 +96,7 @@ func TestGitLabLatestVersion_Release(t *testing.T) {
 				"upcoming_release": false,
 			},
 		})
-	gitLab := gitLabProvider{netloc: "gitlab.something.com", owner: "foo", repo: "bar"}
+	gitLab := gitLabProvider{netloc: "gitlab.something.com", owner: "foo", repo: "bar", httpClient: testHTTPClient}
 
 	result, err := gitLab.LatestVersion()
 
@@ -115,7 +117,7 @@ func TestGitLabLatestVersion_ReleaseWithApiKey(t *testing.T) {
 				"upcoming_release": false,
 			},
 		})
-	gitLab := gitLabProvider{netloc: "gitlab.something.com", owner: "foo", repo: "bar", apiKey: "test_token"}
+	gitLab := gitLabProvider{netloc: "gitlab.something.com", owner: "foo", repo: "bar", apiKey: "test_token", httpClient: testHTTPClient}
 
 	result, err := gitLab.LatestVersion()
 
@@ -139,7 +141,7 @@ func TestGitLabLatestVersion_Tag(t *testing.T) {
 			{"name": "4.1.0"},
 		})
 
-	gitLab := gitLabProvider{netloc: "gitlab.com", owner: "foo", repo: "bar"}
+	gitLab := gitLabProvider{netloc: "gitlab.com", owner: "foo", repo: "bar", httpClient: testHTTPClient}
 
 	result, err := gitLab.LatestVersion()
 
@@ -162,7 +164,7 @@ func TestGitLabLatestVersion_TagWithApiKey(t *testing.T) {
 			{"name": "1.1.1"},
 		})
 
-	gitLab := gitLabProvider{netloc: "gitlab.com", owner: "foo", repo: "bar", apiKey: "test_token"}
+	gitLab := gitLabProvider{netloc: "gitlab.com", owner: "foo", repo: "bar", apiKey: "test_token", httpClient: testHTTPClient}
 
 	result, err := gitLab.LatestVersion()
 
@@ -183,7 +185,7 @@ func TestGitLabLatestVersion_NoVersions(t *testing.T) {
 		Reply(200).
 		JSON([]interface{}{})
 
-	gitLab := gitLabProvider{netloc: "gitlab.com", owner: "foo", repo: "bar"}
+	gitLab := gitLabProvider{netloc: "gitlab.com", owner: "foo", repo: "bar", httpClient: testHTTPClient}
 
 	_, err := gitLab.LatestVersion()
 
@@ -201,7 +203,7 @@ func TestGitLabLatestVersion_4xx(t *testing.T) {
 		Reply(401).
 		JSON([]interface{}{})
 
-	gitLab := gitLabProvider{netloc: "gitlab.com", owner: "foo", repo: "bar"}
+	gitLab := gitLabProvider{netloc: "gitlab.com", owner: "foo", repo: "bar", httpClient: testHTTPClient}
 
 	_, err := gitLab.LatestVersion()
 
@@ -209,19 +211,91 @@ func TestGitLabLatestVersion_4xx(t *testing.T) {
 }
 
 func TestGitLabLatestVersion_5xx(t *testing.T) {
+	httpClient, _ := newFakeClockHTTPClient(t)
 	defer gock.Off()
 	gock.New("https://gitlab.com").
 		Get("/api/v4/projects/foo/bar/releases").
+		Times(maxRetries + 1).
 		Reply(500).
 		JSON([]interface{}{})
 	gock.New("https://gitlab.com").
 		Get("/api/v4/projects/foo/bar/repository/tags").
+		Times(maxRetries + 1).
 		Reply(501).
 		JSON([]interface{}{})
 
-	gitLab := gitLabProvider{netloc: "gitlab.com", owner: "foo", repo: "bar"}
+	gitLab := gitLabProvider{netloc: "gitlab.com", owner: "foo", repo: "bar", httpClient: httpClient}
 
 	_, err := gitLab.LatestVersion()
 
 	assert.ErrorIs(t, err, ErrProviderError)
 }
+
+func TestGitLabLatestVersion_TagPatternAndAsset(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://gitlab.com").
+		Get("/api/v4/projects/foo/bar/releases").
+		Reply(200).
+		JSON([]map[string]interface{}{
+			{"name": "SDK", "tag_name": "sdk-v4.0.0", "assets": map[string]interface{}{"links": []map[string]string{{"name": "sdk-linux-x86_64.tar.gz"}}}},
+			{"name": "CLI without binaries", "tag_name": "cli-v1.3.0", "assets": map[string]interface{}{"links": []interface{}{}}},
+			{"name": "CLI", "tag_name": "cli-v1.2.3", "assets": map[string]interface{}{"links": []map[string]string{{"name": "cli-linux-x86_64.tar.gz"}}}},
+		})
+
+	gitLab := gitLabProvider{
+		netloc:     "gitlab.com",
+		owner:      "foo",
+		repo:       "bar",
+		filter:     versionFilter{tagPattern: regexp.MustCompile(`^cli-v(?P<version>.+)$`), assetPattern: "*-linux-x86_64.tar.gz"},
+		httpClient: testHTTPClient,
+	}
+
+	result, err := gitLab.LatestVersion()
+
+	assert.NoError(t, err)
+	assert.Equal(t, Version("1.2.3"), result)
+}
+
+func TestGitLabLatestRevision(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://gitlab.com").
+		Get("/api/v4/projects/foo/bar/releases").
+		Reply(200).
+		JSON([]map[string]interface{}{})
+	gock.New("https://gitlab.com").
+		Get("/api/v4/projects/foo/bar/repository/tags$").
+		Reply(200).
+		JSON([]map[string]interface{}{{"name": "v1.6.9"}})
+	gock.New("https://gitlab.com").
+		Get("/api/v4/projects/foo/bar/repository/tags/v1.6.9").
+		Reply(200).
+		JSON(map[string]interface{}{"name": "v1.6.9", "commit": map[string]string{"id": "0123456789abcdef0123456789abcdef01234567"}})
+
+	gitLab := gitLabProvider{netloc: "gitlab.com", owner: "foo", repo: "bar", httpClient: testHTTPClient}
+
+	_, err := gitLab.LatestRevision()
+	assert.ErrorIs(t, err, ErrVersionNotFound)
+
+	_, err = gitLab.LatestVersion()
+	assert.NoError(t, err)
+	result, err := gitLab.LatestRevision()
+
+	assert.NoError(t, err)
+	assert.Equal(t, Revision{Tag: "v1.6.9", Commit: "0123456789abcdef0123456789abcdef01234567"}, result)
+	assert.True(t, gock.IsDone())
+}
+
+func TestGitLabLatestReleaseNotes(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://gitlab.com").
+		Get("/api/v4/projects/foo/bar/releases").
+		Reply(200).
+		JSON([]map[string]interface{}{{"name": "Release!", "tag_name": "1.7.0", "description": "Fixed everything"}})
+
+	gitLab := gitLabProvider{netloc: "gitlab.com", owner: "foo", repo: "bar", httpClient: testHTTPClient}
+
+	_, err := gitLab.LatestVersion()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Fixed everything", gitLab.LatestReleaseNotes())
+}
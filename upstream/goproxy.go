@@ -0,0 +1,130 @@
+package upstream
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.uber.org/config"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+const defaultGoProxyBaseURL = "https://proxy.golang.org"
+
+var (
+	goProxyModuleRegex   = regexp.MustCompile(`proxy\.golang\.org/(.+?)/@(v/|latest)`)
+	goGoogleSourceRegex  = regexp.MustCompile(`go\.googlesource\.com/([\w.-]+)`)
+	goGoogleSourcePrefix = "golang.org/x/"
+)
+
+type goProxyProvider struct {
+	rawVersionRecorder
+	modulePath string
+	baseURL    string
+	httpClient *HTTPClient
+}
+
+type goProxyLatestResp struct {
+	Version string `json:"Version"`
+}
+
+// newGoProxyProvider creates goProxyProvider for a module explicitly configured for the package as 'goModule',
+// or for a module found in the URL.
+func newGoProxyProvider(url string, goProxyConfig config.Value, packageConfig config.Value, httpClient *HTTPClient) *goProxyProvider {
+	var modulePath string
+	packageConfig.Get("goModule").Populate(&modulePath) //nolint:errcheck
+	if modulePath == "" {
+		modulePath = goModulePathFromURL(url)
+	}
+	if modulePath == "" {
+		return nil
+	}
+
+	baseURL := defaultGoProxyBaseURL
+	goProxyConfig.Get("baseUrl").Populate(&baseURL) //nolint:errcheck
+
+	return &goProxyProvider{
+		modulePath: modulePath,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: httpClient,
+	}
+}
+
+func goModulePathFromURL(url string) string {
+	if match := goProxyModuleRegex.FindStringSubmatch(url); len(match) != 0 {
+		// module paths in the proxy URLs are case-encoded
+		if modulePath, err := module.UnescapePath(match[1]); err == nil {
+			return modulePath
+		}
+	}
+	if match := goGoogleSourceRegex.FindStringSubmatch(url); len(match) != 0 {
+		return goGoogleSourcePrefix + strings.TrimSuffix(match[1], ".git")
+	}
+	return ""
+}
+
+func (goProxy *goProxyProvider) moduleURL() string {
+	escapedPath, err := module.EscapePath(goProxy.modulePath)
+	if err != nil {
+		escapedPath = goProxy.modulePath
+	}
+	return fmt.Sprintf("%s/%s", goProxy.baseURL, escapedPath)
+}
+
+func (goProxy *goProxyProvider) listURL() string {
+	return goProxy.moduleURL() + "/@v/list"
+}
+
+func (goProxy *goProxyProvider) latestURL() string {
+	return goProxy.moduleURL() + "/@latest"
+}
+
+func (goProxy *goProxyProvider) Equal(other interface{}) bool {
+	switch other := other.(type) {
+	case *goProxyProvider:
+		return goProxy.modulePath == other.modulePath && goProxy.baseURL == other.baseURL
+	default:
+		return false
+	}
+}
+
+// LatestVersion returns the highest released version from the module version list.
+// If the list doesn't contain any such version, the version reported as the latest by the proxy is used.
+func (goProxy *goProxyProvider) LatestVersion() (Version, error) {
+	versionList, err := goProxy.httpClient.getText(goProxy.listURL(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	highestVersion := ""
+	for _, rawVersion := range strings.Fields(versionList) {
+		if isGoReleaseVersion(rawVersion) && semver.Compare(rawVersion, highestVersion) > 0 {
+			highestVersion = rawVersion
+		}
+	}
+
+	if highestVersion == "" {
+		var latest goProxyLatestResp
+		if err := goProxy.httpClient.getJSON(goProxy.latestURL(), &latest, nil); err != nil {
+			return "", err
+		}
+		if isGoReleaseVersion(latest.Version) {
+			highestVersion = latest.Version
+		}
+	}
+
+	if version, isValid := goProxy.parseRawVersion(highestVersion); isValid {
+		return version, nil
+	}
+	return "", ErrVersionNotFound
+}
+
+// isGoReleaseVersion checks whether the module version is a proper release,
+// i.e. not a pseudo-version, prerelease or +incompatible version.
+func isGoReleaseVersion(rawVersion string) bool {
+	return semver.IsValid(rawVersion) &&
+		!module.IsPseudoVersion(rawVersion) &&
+		semver.Prerelease(rawVersion) == "" &&
+		semver.Build(rawVersion) == ""
+}
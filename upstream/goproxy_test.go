@@ -0,0 +1,120 @@
+package upstream
+
+import (
+	"testing"
+
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewGoProxy_Valid(t *testing.T) {
+	cases := map[string]goProxyProvider{
+		"https://proxy.golang.org/github.com/foo/bar/@v/v1.2.3.zip":       {modulePath: "github.com/foo/bar"},
+		"https://proxy.golang.org/github.com/!foo/bar/v2/@v/v2.0.0.zip":   {modulePath: "github.com/Foo/bar/v2"},
+		"https://proxy.golang.org/golang.org/x/tools/@latest":             {modulePath: "golang.org/x/tools"},
+		"https://go.googlesource.com/tools":                               {modulePath: "golang.org/x/tools"},
+		"https://go.googlesource.com/tools.git":                           {modulePath: "golang.org/x/tools"},
+		"https://go.googlesource.com/tools/+archive/refs/tags/v0.1.0.tgz": {modulePath: "golang.org/x/tools"},
+	}
+
+	emptyConfig := testConfig(t, "{}")
+	for validURL, expectedResult := range cases {
+		expectedResult.baseURL = defaultGoProxyBaseURL
+		expectedResult.httpClient = testHTTPClient
+		result := newGoProxyProvider(validURL, emptyConfig, emptyConfig, testHTTPClient)
+		assert.Equal(t, &expectedResult, result)
+	}
+}
+
+func TestNewGoProxy_Invalid(t *testing.T) {
+	invalidURL := "https://github.com/foo/bar"
+	emptyConfig := testConfig(t, "{}")
+
+	result := newGoProxyProvider(invalidURL, emptyConfig, emptyConfig, testHTTPClient)
+	assert.Nil(t, result)
+}
+
+func TestNewGoProxy_Config(t *testing.T) {
+	goProxyConfig := testConfig(t, "{test: {baseUrl: 'http://localhost:3000/'}}")
+	packageConfig := testConfig(t, "{test: {goModule: example.com/vanity/tool}}")
+
+	result := newGoProxyProvider("https://github.com/foo/bar", goProxyConfig, packageConfig, testHTTPClient)
+
+	expected := &goProxyProvider{modulePath: "example.com/vanity/tool", baseURL: "http://localhost:3000", httpClient: testHTTPClient}
+	assert.Equal(t, expected, result)
+}
+
+func TestGoProxyLatestVersion(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://proxy.golang.org").
+		Get("/github.com/!foo/bar/@v/list").
+		Reply(200).
+		BodyString("v1.2.0\nv1.10.0\nv1.9.1\nv2.0.0+incompatible\nv1.11.0-rc.1\nv1.11.1-0.20231010120000-abcdefabcdef\n")
+
+	goProxy := goProxyProvider{modulePath: "github.com/Foo/bar", baseURL: defaultGoProxyBaseURL, httpClient: testHTTPClient}
+
+	result, err := goProxy.LatestVersion()
+
+	assert.NoError(t, err)
+	assert.Equal(t, Version("1.10.0"), result)
+}
+
+func TestGoProxyLatestVersion_Latest(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://proxy.golang.org").
+		Get("/example.com/foo/@v/list").
+		Reply(200).
+		BodyString("")
+	gock.New("https://proxy.golang.org").
+		Get("/example.com/foo/@latest").
+		Reply(200).
+		JSON(map[string]string{"Version": "v0.3.0"})
+
+	goProxy := goProxyProvider{modulePath: "example.com/foo", baseURL: defaultGoProxyBaseURL, httpClient: testHTTPClient}
+
+	result, err := goProxy.LatestVersion()
+
+	assert.NoError(t, err)
+	assert.Equal(t, Version("0.3.0"), result)
+}
+
+func TestGoProxyLatestVersion_OnlyPseudoVersion(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://proxy.golang.org").
+		Get("/example.com/foo/@v/list").
+		Reply(200).
+		BodyString("")
+	gock.New("https://proxy.golang.org").
+		Get("/example.com/foo/@latest").
+		Reply(200).
+		JSON(map[string]string{"Version": "v0.0.0-20231010120000-abcdefabcdef"})
+
+	goProxy := goProxyProvider{modulePath: "example.com/foo", baseURL: defaultGoProxyBaseURL, httpClient: testHTTPClient}
+
+	_, err := goProxy.LatestVersion()
+
+	assert.ErrorIs(t, err, ErrVersionNotFound)
+}
+
+func TestGoProxyLatestVersion_4xx(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://proxy.golang.org").
+		Get("/example.com/foo/@v/list").
+		Reply(404).
+		BodyString("not found")
+
+	goProxy := goProxyProvider{modulePath: "example.com/foo", baseURL: defaultGoProxyBaseURL, httpClient: testHTTPClient}
+
+	_, err := goProxy.LatestVersion()
+
+	assert.ErrorIs(t, err, ErrVersionNotFound)
+}
+
+func TestGoProxyEqual(t *testing.T) {
+	goProxy := &goProxyProvider{modulePath: "example.com/foo", baseURL: defaultGoProxyBaseURL}
+
+	assert.True(t, goProxy.Equal(&goProxyProvider{modulePath: "example.com/foo", baseURL: defaultGoProxyBaseURL}))
+	assert.False(t, goProxy.Equal(&goProxyProvider{modulePath: "example.com/bar", baseURL: defaultGoProxyBaseURL}))
+	assert.False(t, goProxy.Equal(&goProxyProvider{modulePath: "example.com/foo", baseURL: "http://localhost"}))
+	assert.False(t, goProxy.Equal(&pypiProvider{packageName: "foo"}))
+}
@@ -0,0 +1,105 @@
+package upstream
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.uber.org/config"
+)
+
+const defaultHackageBaseURL = "https://hackage.haskell.org"
+
+var (
+	hackageArchiveRegex = regexp.MustCompile(`hackage\.haskell\.org/packages/archive/([\w-]+)/`)
+	hackagePackageRegex = regexp.MustCompile(`hackage\.haskell\.org/package/([\w-]+?)(-\d+(\.\d+)*)?(/|$|[#?])`)
+)
+
+type hackageProvider struct {
+	rawVersionRecorder
+	packageName string
+	baseURL     string
+	httpClient  *HTTPClient
+}
+
+type hackagePreferredResp struct {
+	NormalVersions     []string `json:"normal-version"`
+	DeprecatedVersions []string `json:"deprecated-version"`
+}
+
+func newHackageProvider(url string, hackageConfig config.Value, httpClient *HTTPClient) *hackageProvider {
+	var packageName string
+	if match := hackageArchiveRegex.FindStringSubmatch(url); len(match) != 0 {
+		packageName = match[1]
+	} else if match := hackagePackageRegex.FindStringSubmatch(url); len(match) != 0 {
+		packageName = match[1]
+	} else {
+		return nil
+	}
+
+	baseURL := defaultHackageBaseURL
+	hackageConfig.Get("baseUrl").Populate(&baseURL) //nolint:errcheck
+
+	return &hackageProvider{
+		packageName: packageName,
+		baseURL:     strings.TrimSuffix(baseURL, "/"),
+		httpClient:  httpClient,
+	}
+}
+
+func (hackage *hackageProvider) preferredURL() string {
+	return fmt.Sprintf("%s/package/%s/preferred", hackage.baseURL, hackage.packageName)
+}
+
+func (hackage *hackageProvider) Equal(other interface{}) bool {
+	switch other := other.(type) {
+	case *hackageProvider:
+		return hackage.packageName == other.packageName && hackage.baseURL == other.baseURL
+	default:
+		return false
+	}
+}
+
+// LatestVersion returns the highest of the preferred package versions, deprecated versions are never considered.
+func (hackage *hackageProvider) LatestVersion() (Version, error) {
+	var preferred hackagePreferredResp
+	headers := map[string]string{"Accept": "application/json"}
+	if err := hackage.httpClient.getJSON(hackage.preferredURL(), &preferred, headers); err != nil {
+		return "", err
+	}
+
+	highestVersion := ""
+	for _, rawVersion := range preferred.NormalVersions {
+		if slices.Contains(preferred.DeprecatedVersions, rawVersion) {
+			continue
+		}
+		if compareHackageVersions(rawVersion, highestVersion) > 0 {
+			highestVersion = rawVersion
+		}
+	}
+
+	if version, isValid := hackage.parseRawVersion(highestVersion); isValid {
+		return version, nil
+	}
+	return "", ErrVersionNotFound
+}
+
+// compareHackageVersions compares two Haskell package versions, which consist of numeric components only.
+// Returns -1, 0 or 1, like slices.Compare. Empty string is lower than any version.
+func compareHackageVersions(a, b string) int {
+	return slices.Compare(hackageVersionComponents(a), hackageVersionComponents(b))
+}
+
+func hackageVersionComponents(version string) []int {
+	if version == "" {
+		return nil
+	}
+	components := []int{}
+	for _, component := range strings.Split(version, ".") {
+		number, _ := strconv.Atoi(component)
+		components = append(components, number)
+	}
+	return components
+}
@@ -0,0 +1,99 @@
+package upstream
+
+import (
+	"testing"
+
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHackage_Valid(t *testing.T) {
+	cases := map[string]hackageProvider{
+		"https://hackage.haskell.org/packages/archive/foo-bar/1.2.3/foo-bar-1.2.3.tar.gz": {packageName: "foo-bar"},
+		"https://hackage.haskell.org/package/foo-bar-1.2.3/foo-bar-1.2.3.tar.gz":          {packageName: "foo-bar"},
+		"https://hackage.haskell.org/package/aeson2-2.2.0.0/aeson2-2.2.0.0.tar.gz":        {packageName: "aeson2"},
+		"https://hackage.haskell.org/package/pandoc":                                      {packageName: "pandoc"},
+		"https://hackage.haskell.org/package/pandoc/":                                     {packageName: "pandoc"},
+	}
+
+	emptyConfig := testConfig(t, "{}")
+	for validURL, expectedResult := range cases {
+		expectedResult.baseURL = defaultHackageBaseURL
+		expectedResult.httpClient = testHTTPClient
+		result := newHackageProvider(validURL, emptyConfig, testHTTPClient)
+		assert.Equal(t, &expectedResult, result, validURL)
+	}
+}
+
+func TestNewHackage_Invalid(t *testing.T) {
+	invalidURL := "https://whatever.url/package/foo-1.2.3/foo-1.2.3.tar.gz"
+
+	result := newHackageProvider(invalidURL, testConfig(t, "{}"), testHTTPClient)
+	assert.Nil(t, result)
+}
+
+func TestNewHackage_BaseURL(t *testing.T) {
+	hackageConfig := testConfig(t, "{test: {baseUrl: 'http://localhost:8080/'}}")
+
+	result := newHackageProvider("https://hackage.haskell.org/package/pandoc", hackageConfig, testHTTPClient)
+
+	assert.Equal(t, "http://localhost:8080", result.baseURL)
+}
+
+func TestHackageLatestVersion(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://hackage.haskell.org").
+		Get("/package/foo/preferred").
+		MatchHeader("Accept", "application/json").
+		Reply(200).
+		JSON(map[string][]string{
+			"normal-version":     {"1.9.0", "1.10.0.1", "1.10.0", "0.5"},
+			"deprecated-version": {"1.11.0"},
+		})
+
+	hackage := hackageProvider{packageName: "foo", baseURL: defaultHackageBaseURL, httpClient: testHTTPClient}
+
+	result, err := hackage.LatestVersion()
+
+	assert.NoError(t, err)
+	assert.Equal(t, Version("1.10.0.1"), result)
+}
+
+func TestHackageLatestVersion_OnlyDeprecated(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://hackage.haskell.org").
+		Get("/package/foo/preferred").
+		Reply(200).
+		JSON(map[string][]string{
+			"normal-version":     {"1.0.0"},
+			"deprecated-version": {"1.0.0"},
+		})
+
+	hackage := hackageProvider{packageName: "foo", baseURL: defaultHackageBaseURL, httpClient: testHTTPClient}
+
+	_, err := hackage.LatestVersion()
+
+	assert.ErrorIs(t, err, ErrVersionNotFound)
+}
+
+func TestHackageLatestVersion_4xx(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://hackage.haskell.org").
+		Get("/package/foo/preferred").
+		Reply(404).
+		BodyString("Package not found")
+
+	hackage := hackageProvider{packageName: "foo", baseURL: defaultHackageBaseURL, httpClient: testHTTPClient}
+
+	_, err := hackage.LatestVersion()
+
+	assert.ErrorIs(t, err, ErrVersionNotFound)
+}
+
+func TestHackageEqual(t *testing.T) {
+	hackage := &hackageProvider{packageName: "foo", baseURL: defaultHackageBaseURL}
+
+	assert.True(t, hackage.Equal(&hackageProvider{packageName: "foo", baseURL: defaultHackageBaseURL}))
+	assert.False(t, hackage.Equal(&hackageProvider{packageName: "bar", baseURL: defaultHackageBaseURL}))
+	assert.False(t, hackage.Equal(&pypiProvider{packageName: "foo"}))
+}
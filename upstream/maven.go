@@ -0,0 +1,92 @@
+package upstream
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.uber.org/config"
+)
+
+const defaultMavenBaseURL = "https://repo1.maven.org/maven2"
+
+// Matches <group path>/<artifact>/<version>/<file> in the Maven Central repository
+var mavenCentralRegex = regexp.MustCompile(`(repo1\.maven\.org|repo\.maven\.apache\.org)/maven2/([^#?]+)`)
+
+type mavenProvider struct {
+	rawVersionRecorder
+	groupID    string
+	artifactID string
+	baseURL    string
+	httpClient *HTTPClient
+}
+
+type mavenMetadataResp struct {
+	Release  string   `xml:"versioning>release"`
+	Versions []string `xml:"versioning>versions>version"`
+}
+
+// newMavenProvider creates mavenProvider for Maven Central URLs and URLs within the configured repository base.
+func newMavenProvider(url string, mavenConfig config.Value, httpClient *HTTPClient) *mavenProvider {
+	baseURL := defaultMavenBaseURL
+	mavenConfig.Get("baseUrl").Populate(&baseURL) //nolint:errcheck
+	baseURL = strings.TrimSuffix(baseURL, "/")
+
+	var artifactPath string
+	if match := mavenCentralRegex.FindStringSubmatch(url); len(match) != 0 {
+		artifactPath = match[2]
+	} else if strings.HasPrefix(url, baseURL+"/") {
+		artifactPath = strings.TrimPrefix(url, baseURL+"/")
+	} else {
+		return nil
+	}
+
+	// at least one group segment, artifact, version and file name
+	segments := strings.Split(artifactPath, "/")
+	if len(segments) < 4 {
+		return nil
+	}
+	groupSegments := segments[:len(segments)-3]
+
+	return &mavenProvider{
+		groupID:    strings.Join(groupSegments, "."),
+		artifactID: segments[len(segments)-3],
+		baseURL:    baseURL,
+		httpClient: httpClient,
+	}
+}
+
+func (maven *mavenProvider) metadataURL() string {
+	groupPath := strings.ReplaceAll(maven.groupID, ".", "/")
+	return fmt.Sprintf("%s/%s/%s/maven-metadata.xml", maven.baseURL, groupPath, maven.artifactID)
+}
+
+func (maven *mavenProvider) Equal(other interface{}) bool {
+	switch other := other.(type) {
+	case *mavenProvider:
+		return maven.groupID == other.groupID && maven.artifactID == other.artifactID && maven.baseURL == other.baseURL
+	default:
+		return false
+	}
+}
+
+// LatestVersion returns the release version from the artifact metadata.
+// If it's missing, the newest version from the versions list is used. Snapshots are never considered.
+func (maven *mavenProvider) LatestVersion() (Version, error) {
+	var metadata mavenMetadataResp
+	if err := maven.httpClient.getXML(maven.metadataURL(), &metadata, nil); err != nil {
+		return "", err
+	}
+
+	if version, isValid := maven.parseRawVersion(metadata.Release); isValid {
+		return version, nil
+	}
+	// Versions are listed from the oldest
+	for i := len(metadata.Versions) - 1; i >= 0; i-- {
+		if version, isValid := maven.parseRawVersion(metadata.Versions[i]); isValid {
+			return version, nil
+		}
+	}
+
+	return "", ErrVersionNotFound
+}
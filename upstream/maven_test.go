@@ -0,0 +1,114 @@
+package upstream
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+)
+
+const mavenMetadata = `<?xml version="1.0" encoding="UTF-8"?>
+<metadata>
+  <groupId>com.example.foo</groupId>
+  <artifactId>bar-cli</artifactId>
+  <versioning>
+    <latest>2.0.0-SNAPSHOT</latest>
+    <release>%s</release>
+    <versions>
+      <version>1.0.0</version>
+      <version>1.1.0</version>
+      <version>2.0.0-SNAPSHOT</version>
+    </versions>
+  </versioning>
+</metadata>`
+
+func TestNewMaven_Valid(t *testing.T) {
+	cases := map[string]mavenProvider{
+		"https://repo1.maven.org/maven2/com/example/foo/bar-cli/1.1.0/bar-cli-1.1.0.jar":       {groupID: "com.example.foo", artifactID: "bar-cli"},
+		"https://repo.maven.apache.org/maven2/org/foo/bar/1.0/bar-1.0-bin.zip":                 {groupID: "org.foo", artifactID: "bar"},
+		"https://repo1.maven.org/maven2/io/foo/bar_2.13/0.1.0/bar_2.13-0.1.0-assembly.jar?x=y": {groupID: "io.foo", artifactID: "bar_2.13"},
+	}
+
+	emptyConfig := testConfig(t, "{}")
+	for validURL, expectedResult := range cases {
+		expectedResult.baseURL = defaultMavenBaseURL
+		expectedResult.httpClient = testHTTPClient
+		result := newMavenProvider(validURL, emptyConfig, testHTTPClient)
+		assert.Equal(t, &expectedResult, result, validURL)
+	}
+}
+
+func TestNewMaven_Invalid(t *testing.T) {
+	cases := []string{
+		"https://whatever.url/maven2/com/example/foo/bar-cli/1.1.0/bar-cli-1.1.0.jar",
+		"https://repo1.maven.org/maven2/bar-cli/1.1.0/bar-cli-1.1.0.jar",
+	}
+
+	emptyConfig := testConfig(t, "{}")
+	for _, invalidURL := range cases {
+		result := newMavenProvider(invalidURL, emptyConfig, testHTTPClient)
+		assert.Nil(t, result, invalidURL)
+	}
+}
+
+func TestNewMaven_BaseURL(t *testing.T) {
+	mavenConfig := testConfig(t, "{test: {baseUrl: 'https://mirror.example.com/maven/'}}")
+
+	result := newMavenProvider("https://mirror.example.com/maven/com/foo/bar/1.0/bar-1.0.jar", mavenConfig, testHTTPClient)
+
+	expected := &mavenProvider{groupID: "com.foo", artifactID: "bar", baseURL: "https://mirror.example.com/maven", httpClient: testHTTPClient}
+	assert.Equal(t, expected, result)
+}
+
+func TestMavenLatestVersion(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://repo1.maven.org").
+		Get("/maven2/com/example/foo/bar-cli/maven-metadata.xml").
+		Reply(200).
+		BodyString(fmt.Sprintf(mavenMetadata, "1.1.0"))
+
+	maven := mavenProvider{groupID: "com.example.foo", artifactID: "bar-cli", baseURL: defaultMavenBaseURL, httpClient: testHTTPClient}
+
+	result, err := maven.LatestVersion()
+
+	assert.NoError(t, err)
+	assert.Equal(t, Version("1.1.0"), result)
+}
+
+func TestMavenLatestVersion_NoRelease(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://repo1.maven.org").
+		Get("/maven2/com/example/foo/bar-cli/maven-metadata.xml").
+		Reply(200).
+		BodyString(fmt.Sprintf(mavenMetadata, ""))
+
+	maven := mavenProvider{groupID: "com.example.foo", artifactID: "bar-cli", baseURL: defaultMavenBaseURL, httpClient: testHTTPClient}
+
+	result, err := maven.LatestVersion()
+
+	assert.NoError(t, err)
+	assert.Equal(t, Version("1.1.0"), result)
+}
+
+func TestMavenLatestVersion_4xx(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://repo1.maven.org").
+		Get("/maven2/com/example/foo/bar-cli/maven-metadata.xml").
+		Reply(404).
+		BodyString("Not found")
+
+	maven := mavenProvider{groupID: "com.example.foo", artifactID: "bar-cli", baseURL: defaultMavenBaseURL, httpClient: testHTTPClient}
+
+	_, err := maven.LatestVersion()
+
+	assert.ErrorIs(t, err, ErrVersionNotFound)
+}
+
+func TestMavenEqual(t *testing.T) {
+	maven := &mavenProvider{groupID: "com.foo", artifactID: "bar", baseURL: defaultMavenBaseURL}
+
+	assert.True(t, maven.Equal(&mavenProvider{groupID: "com.foo", artifactID: "bar", baseURL: defaultMavenBaseURL}))
+	assert.False(t, maven.Equal(&mavenProvider{groupID: "com.foo", artifactID: "baz", baseURL: defaultMavenBaseURL}))
+	assert.False(t, maven.Equal(&pypiProvider{packageName: "bar"}))
+}
@@ -0,0 +1,149 @@
+package upstream
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/bcyran/bumper/internal/vercmp"
+	"go.uber.org/config"
+)
+
+const (
+	defaultMetaCPANBaseURL = "https://fastapi.metacpan.org"
+	metaCPANReleased       = "released"
+)
+
+var (
+	cpanAuthorsRegex  = regexp.MustCompile(`(cpan\.metacpan\.org|cpan\.org|/CPAN)/authors/id/\w/\w{2}/[\w-]+/(?:[^/#?]+/)*([^/#?]+?)-v?\d[^/#?]*\.(tar\.gz|tgz|tar\.bz2|tar\.xz|zip)`)
+	metaCPANDistRegex = regexp.MustCompile(`metacpan\.org/(dist|release)/([\w-]+)`)
+)
+
+type metaCPANProvider struct {
+	rawVersionRecorder
+	distName string
+	baseURL  string
+	// dotted enables conversion of decimal versions to the dotted form, for packages which use it in pkgver
+	dotted     bool
+	httpClient *HTTPClient
+}
+
+type metaCPANReleaseResp struct {
+	Version  string `json:"version"`
+	Maturity string `json:"maturity"`
+}
+
+func newMetaCPANProvider(url string, metaCPANConfig config.Value, packageConfig config.Value, httpClient *HTTPClient) *metaCPANProvider {
+	var distName string
+	if match := cpanAuthorsRegex.FindStringSubmatch(url); len(match) != 0 {
+		distName = match[2]
+	} else if match := metaCPANDistRegex.FindStringSubmatch(url); len(match) != 0 {
+		distName = match[2]
+	} else {
+		return nil
+	}
+
+	baseURL := defaultMetaCPANBaseURL
+	metaCPANConfig.Get("baseUrl").Populate(&baseURL) //nolint:errcheck
+
+	var dotted bool
+	packageConfig.Get("cpanDottedVersion").Populate(&dotted) //nolint:errcheck
+
+	return &metaCPANProvider{
+		distName:   distName,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		dotted:     dotted,
+		httpClient: httpClient,
+	}
+}
+
+func (metaCPAN *metaCPANProvider) releaseURL() string {
+	return fmt.Sprintf("%s/v1/release/%s", metaCPAN.baseURL, metaCPAN.distName)
+}
+
+func (metaCPAN *metaCPANProvider) Equal(other interface{}) bool {
+	switch other := other.(type) {
+	case *metaCPANProvider:
+		return metaCPAN.distName == other.distName && metaCPAN.baseURL == other.baseURL
+	default:
+		return false
+	}
+}
+
+func (metaCPAN *metaCPANProvider) LatestVersion() (Version, error) {
+	var release metaCPANReleaseResp
+	if err := metaCPAN.httpClient.getJSON(metaCPAN.releaseURL(), &release, nil); err != nil {
+		return "", err
+	}
+	if release.Maturity != metaCPANReleased {
+		return "", ErrVersionNotFound
+	}
+	version := normalizeCPANVersion(release.Version)
+	if metaCPAN.dotted {
+		version = comparableCPANVersion(version)
+	}
+	if version, isValid := ParseVersion(version); isValid {
+		metaCPAN.latestRawVersion = strings.TrimSpace(release.Version)
+		return version, nil
+	}
+	return "", ErrVersionNotFound
+}
+
+// CompareVersions compares decimal versions as decimals, e.g. 1.1 is newer than 1.023.
+// Both versions are converted to the dotted form for the comparison only, pkgver keeps the published form.
+func (metaCPAN *metaCPANProvider) CompareVersions(a string, b string) int {
+	return vercmp.Rpmvercmp(comparableCPANVersion(a), comparableCPANVersion(b))
+}
+
+// normalizeCPANVersion converts CPAN version string to the form used in pkgver.
+// Dotted-decimal versions (e.g. v1.2.3) lose the 'v' prefix. Decimal versions (e.g. 1.023) are kept
+// as published, including the leading zeros of the fractional part, because pkgver keeps them too.
+// Underscores of alpha versions and the -TRIAL suffix are removed.
+func normalizeCPANVersion(rawVersion string) string {
+	version := strings.TrimSpace(rawVersion)
+	version = strings.TrimSuffix(version, "-TRIAL")
+	version = strings.ReplaceAll(version, "_", "")
+	return strings.TrimPrefix(version, "v")
+}
+
+// comparableCPANVersion converts decimal version to the dotted form, e.g. 1.023 becomes 1.23.0
+// and 1.1 becomes 1.100.0. Other versions are returned unchanged.
+func comparableCPANVersion(version string) string {
+	if strings.Count(version, ".") != 1 {
+		return version
+	}
+	return decimalToDotted(version)
+}
+
+// decimalToDotted converts decimal version to the dotted form, the same way Perl's version module does.
+// The fraction is split into groups of three digits, the last one is right-padded with zeros,
+// and there are at least three components, e.g. 1.02345 becomes 1.23.450, 0.9 becomes 0.900.0.
+func decimalToDotted(version string) string {
+	integer, fraction, _ := strings.Cut(version, ".")
+	if !isDigits(integer) || !isDigits(fraction) {
+		return version
+	}
+	if remainder := len(fraction) % 3; remainder != 0 {
+		fraction += strings.Repeat("0", 3-remainder)
+	}
+
+	components := []string{trimLeadingZeros(integer)}
+	for i := 0; i < len(fraction); i += 3 {
+		components = append(components, trimLeadingZeros(fraction[i:i+3]))
+	}
+	for len(components) < 3 {
+		components = append(components, "0")
+	}
+	return strings.Join(components, ".")
+}
+
+func isDigits(str string) bool {
+	return str != "" && strings.Trim(str, "0123456789") == ""
+}
+
+func trimLeadingZeros(number string) string {
+	if trimmed := strings.TrimLeft(number, "0"); trimmed != "" {
+		return trimmed
+	}
+	return "0"
+}
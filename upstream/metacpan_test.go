@@ -0,0 +1,154 @@
+package upstream
+
+import (
+	"testing"
+
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMetaCPAN_Valid(t *testing.T) {
+	cases := map[string]metaCPANProvider{
+		"https://cpan.metacpan.org/authors/id/A/AU/AUTHOR/Dist-Name-1.23.tar.gz":       {distName: "Dist-Name"},
+		"https://cpan.metacpan.org/authors/id/A/AU/AUTHOR/Foo-v1.2.3.tar.gz":           {distName: "Foo"},
+		"https://www.cpan.org/authors/id/T/TI/TIMB/DBI-1.643.tar.gz":                   {distName: "DBI"},
+		"https://search.cpan.org/CPAN/authors/id/E/ET/ETHER/Try-Tiny-0.31.tar.gz":      {distName: "Try-Tiny"},
+		"https://cpan.metacpan.org/authors/id/A/AU/AUTHOR/subdir/Foo-Bar-2.001_01.tgz": {distName: "Foo-Bar"},
+		"https://metacpan.org/dist/Moose":                                              {distName: "Moose"},
+		"https://metacpan.org/release/Foo-Bar":                                         {distName: "Foo-Bar"},
+	}
+
+	emptyConfig := testConfig(t, "{}")
+	for validURL, expectedResult := range cases {
+		expectedResult.baseURL = defaultMetaCPANBaseURL
+		expectedResult.httpClient = testHTTPClient
+		result := newMetaCPANProvider(validURL, emptyConfig, emptyConfig, testHTTPClient)
+		assert.Equal(t, &expectedResult, result, validURL)
+	}
+}
+
+func TestNewMetaCPAN_Invalid(t *testing.T) {
+	invalidURL := "https://whatever.url/authors/id/A/AU/AUTHOR/Dist-Name-1.23.tar.gz"
+
+	result := newMetaCPANProvider(invalidURL, testConfig(t, "{}"), testConfig(t, "{}"), testHTTPClient)
+	assert.Nil(t, result)
+}
+
+func TestNewMetaCPAN_BaseURL(t *testing.T) {
+	metaCPANConfig := testConfig(t, "{test: {baseUrl: 'http://localhost:5000/'}}")
+
+	result := newMetaCPANProvider("https://metacpan.org/dist/Moose", metaCPANConfig, testConfig(t, "{}"), testHTTPClient)
+
+	assert.Equal(t, "http://localhost:5000", result.baseURL)
+}
+
+func TestMetaCPANLatestVersion(t *testing.T) {
+	cases := map[string]Version{
+		"1.023":        "1.023",
+		"v1.2.3":       "1.2.3",
+		"1.2.3":        "1.2.3",
+		"0.31":         "0.31",
+		"2.001_01":     "2.00101",
+		"1.5-TRIAL":    "1.5",
+		" 1.643 ":      "1.643",
+		"20231010.001": "20231010.001",
+	}
+
+	for rawVersion, expectedVersion := range cases {
+		gock.New("https://fastapi.metacpan.org").
+			Get("/v1/release/Dist-Name").
+			Reply(200).
+			JSON(map[string]string{"version": rawVersion, "maturity": "released"})
+
+		metaCPAN := metaCPANProvider{distName: "Dist-Name", baseURL: defaultMetaCPANBaseURL, httpClient: testHTTPClient}
+
+		result, err := metaCPAN.LatestVersion()
+
+		assert.NoError(t, err)
+		assert.Equal(t, expectedVersion, result)
+		gock.Off()
+	}
+}
+
+func TestMetaCPANLatestVersion_Dotted(t *testing.T) {
+	cases := map[string]Version{
+		"1.023":    "1.23.0",
+		"1.1":      "1.100.0",
+		"1.02345":  "1.23.450",
+		"2.001_01": "2.1.10",
+		"v1.2.3":   "1.2.3",
+	}
+
+	for rawVersion, expectedVersion := range cases {
+		gock.New("https://fastapi.metacpan.org").
+			Get("/v1/release/Dist-Name").
+			Reply(200).
+			JSON(map[string]string{"version": rawVersion, "maturity": "released"})
+
+		metaCPAN := newMetaCPANProvider("https://metacpan.org/dist/Dist-Name", testConfig(t, "{}"), testConfig(t, "{test: {cpanDottedVersion: true}}"), testHTTPClient)
+
+		result, err := metaCPAN.LatestVersion()
+
+		assert.NoError(t, err)
+		assert.Equal(t, expectedVersion, result)
+		gock.Off()
+	}
+}
+
+func TestMetaCPANCompareVersions(t *testing.T) {
+	metaCPAN := metaCPANProvider{distName: "Dist-Name"}
+	// pairs of decimal versions, the first one is older, while plain rpmvercmp thinks otherwise
+	cases := [][2]string{
+		{"1.023", "1.1"},
+		// 0.10 is 0.100 and 0.9 is 0.900
+		{"0.10", "0.9"},
+		{"1.09", "1.1"},
+		{"1.0099", "1.01"},
+		{"1.20", "1.3"},
+	}
+
+	for _, versions := range cases {
+		assert.Equal(t, -1, metaCPAN.CompareVersions(versions[0], versions[1]), "%s < %s", versions[0], versions[1])
+		assert.Equal(t, 1, metaCPAN.CompareVersions(versions[1], versions[0]), "%s > %s", versions[1], versions[0])
+	}
+	// pkgver already equal to the latest release is up to date
+	for _, version := range []string{"1.023", "0.31", "1.2.3", "1.23.0"} {
+		assert.Equal(t, 0, metaCPAN.CompareVersions(version, version), version)
+	}
+}
+
+func TestMetaCPANLatestVersion_Developer(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://fastapi.metacpan.org").
+		Get("/v1/release/Dist-Name").
+		Reply(200).
+		JSON(map[string]string{"version": "1.024_01", "maturity": "developer"})
+
+	metaCPAN := metaCPANProvider{distName: "Dist-Name", baseURL: defaultMetaCPANBaseURL, httpClient: testHTTPClient}
+
+	_, err := metaCPAN.LatestVersion()
+
+	assert.ErrorIs(t, err, ErrVersionNotFound)
+}
+
+func TestMetaCPANLatestVersion_4xx(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://fastapi.metacpan.org").
+		Get("/v1/release/Dist-Name").
+		Reply(404).
+		JSON(map[string]string{"message": "Not found", "code": "404"})
+
+	metaCPAN := metaCPANProvider{distName: "Dist-Name", baseURL: defaultMetaCPANBaseURL, httpClient: testHTTPClient}
+
+	_, err := metaCPAN.LatestVersion()
+
+	assert.ErrorIs(t, err, ErrVersionNotFound)
+}
+
+func TestMetaCPANEqual(t *testing.T) {
+	metaCPAN := &metaCPANProvider{distName: "Foo", baseURL: defaultMetaCPANBaseURL}
+
+	assert.True(t, metaCPAN.Equal(&metaCPANProvider{distName: "Foo", baseURL: defaultMetaCPANBaseURL}))
+	assert.False(t, metaCPAN.Equal(&metaCPANProvider{distName: "Bar", baseURL: defaultMetaCPANBaseURL}))
+	assert.False(t, metaCPAN.Equal(&pypiProvider{packageName: "Foo"}))
+}
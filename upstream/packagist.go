@@ -0,0 +1,77 @@
+package upstream
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.uber.org/config"
+)
+
+const defaultPackagistBaseURL = "https://repo.packagist.org"
+
+var packagistPackageRegex = regexp.MustCompile(`(packagist\.org/packages|repo\.packagist\.org/p2?)/([\w.-]+)/([\w.-]+?)(\.json)?(/|$|[#?])`)
+
+type packagistProvider struct {
+	rawVersionRecorder
+	vendor     string
+	name       string
+	baseURL    string
+	httpClient *HTTPClient
+}
+
+type packagistPackageResp struct {
+	Packages map[string][]struct {
+		Version string `json:"version"`
+	} `json:"packages"`
+}
+
+func newPackagistProvider(url string, packagistConfig config.Value, httpClient *HTTPClient) *packagistProvider {
+	match := packagistPackageRegex.FindStringSubmatch(url)
+	if len(match) == 0 {
+		return nil
+	}
+
+	baseURL := defaultPackagistBaseURL
+	packagistConfig.Get("baseUrl").Populate(&baseURL) //nolint:errcheck
+
+	return &packagistProvider{
+		vendor:     match[2],
+		name:       match[3],
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: httpClient,
+	}
+}
+
+func (packagist *packagistProvider) packageName() string {
+	return packagist.vendor + "/" + packagist.name
+}
+
+func (packagist *packagistProvider) packageURL() string {
+	return fmt.Sprintf("%s/p2/%s.json", packagist.baseURL, packagist.packageName())
+}
+
+func (packagist *packagistProvider) Equal(other interface{}) bool {
+	switch other := other.(type) {
+	case *packagistProvider:
+		return packagist.packageName() == other.packageName() && packagist.baseURL == other.baseURL
+	default:
+		return false
+	}
+}
+
+func (packagist *packagistProvider) LatestVersion() (Version, error) {
+	var packageResp packagistPackageResp
+	if err := packagist.httpClient.getJSON(packagist.packageURL(), &packageResp, nil); err != nil {
+		return "", err
+	}
+
+	// Tagged versions are listed from the newest, unstable ones (e.g. 2.0.0-RC1) are not valid Versions
+	for _, release := range packageResp.Packages[packagist.packageName()] {
+		if version, isValid := packagist.parseRawVersion(release.Version); isValid {
+			return version, nil
+		}
+	}
+
+	return "", ErrVersionNotFound
+}
@@ -0,0 +1,99 @@
+package upstream
+
+import (
+	"testing"
+
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPackagist_Valid(t *testing.T) {
+	cases := map[string]packagistProvider{
+		"https://packagist.org/packages/foo/bar":            {vendor: "foo", name: "bar"},
+		"https://packagist.org/packages/foo/bar-baz#v1.2.3": {vendor: "foo", name: "bar-baz"},
+		"https://repo.packagist.org/p2/foo/bar.json":        {vendor: "foo", name: "bar"},
+		"https://repo.packagist.org/p/foo/bar.json":         {vendor: "foo", name: "bar"},
+	}
+
+	emptyConfig := testConfig(t, "{}")
+	for validURL, expectedResult := range cases {
+		expectedResult.baseURL = defaultPackagistBaseURL
+		expectedResult.httpClient = testHTTPClient
+		result := newPackagistProvider(validURL, emptyConfig, testHTTPClient)
+		assert.Equal(t, &expectedResult, result, validURL)
+	}
+}
+
+func TestNewPackagist_Invalid(t *testing.T) {
+	invalidURL := "https://whatever.url/packages/foo/bar"
+
+	result := newPackagistProvider(invalidURL, testConfig(t, "{}"), testHTTPClient)
+	assert.Nil(t, result)
+}
+
+func TestNewPackagist_BaseURL(t *testing.T) {
+	packagistConfig := testConfig(t, "{test: {baseUrl: 'http://localhost:8000/'}}")
+
+	result := newPackagistProvider("https://packagist.org/packages/foo/bar", packagistConfig, testHTTPClient)
+
+	assert.Equal(t, "http://localhost:8000", result.baseURL)
+}
+
+func TestPackagistLatestVersion(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://repo.packagist.org").
+		Get("/p2/foo/bar.json").
+		Reply(200).
+		JSON(map[string]interface{}{
+			"packages": map[string]interface{}{
+				"foo/bar": []map[string]string{
+					{"version": "v2.0.0-RC1", "version_normalized": "2.0.0.0-RC1"},
+					{"version": "v1.5.2", "version_normalized": "1.5.2.0"},
+					{"version": "v1.5.1", "version_normalized": "1.5.1.0"},
+				},
+			},
+		})
+
+	packagist := packagistProvider{vendor: "foo", name: "bar", baseURL: defaultPackagistBaseURL, httpClient: testHTTPClient}
+
+	result, err := packagist.LatestVersion()
+
+	assert.NoError(t, err)
+	assert.Equal(t, Version("1.5.2"), result)
+}
+
+func TestPackagistLatestVersion_NoVersions(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://repo.packagist.org").
+		Get("/p2/foo/bar.json").
+		Reply(200).
+		JSON(map[string]interface{}{"packages": map[string]interface{}{"foo/bar": []interface{}{}}})
+
+	packagist := packagistProvider{vendor: "foo", name: "bar", baseURL: defaultPackagistBaseURL, httpClient: testHTTPClient}
+
+	_, err := packagist.LatestVersion()
+
+	assert.ErrorIs(t, err, ErrVersionNotFound)
+}
+
+func TestPackagistLatestVersion_4xx(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://repo.packagist.org").
+		Get("/p2/foo/bar.json").
+		Reply(404).
+		BodyString("Not found")
+
+	packagist := packagistProvider{vendor: "foo", name: "bar", baseURL: defaultPackagistBaseURL, httpClient: testHTTPClient}
+
+	_, err := packagist.LatestVersion()
+
+	assert.ErrorIs(t, err, ErrVersionNotFound)
+}
+
+func TestPackagistEqual(t *testing.T) {
+	packagist := &packagistProvider{vendor: "foo", name: "bar", baseURL: defaultPackagistBaseURL}
+
+	assert.True(t, packagist.Equal(&packagistProvider{vendor: "foo", name: "bar", baseURL: defaultPackagistBaseURL}))
+	assert.False(t, packagist.Equal(&packagistProvider{vendor: "foo", name: "baz", baseURL: defaultPackagistBaseURL}))
+	assert.False(t, packagist.Equal(&pypiProvider{packageName: "bar"}))
+}
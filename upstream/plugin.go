@@ -0,0 +1,116 @@
+package upstream
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bcyran/bumper/internal/vercmp"
+	"go.uber.org/config"
+)
+
+// CommandRunner runs the command in the given working directory and returns its stdout.
+type CommandRunner = func(cwd string, command string, args ...string) ([]byte, error)
+
+// EnvCommandRunner is a CommandRunner which also adds the given variables to the environment of the command.
+type EnvCommandRunner = func(cwd string, env []string, command string, args ...string) ([]byte, error)
+
+// PluginPackage describes the package checked by a plugin.
+// It's passed to the plugin JSON encoded in BUMPER_PACKAGE environment variable.
+type PluginPackage struct {
+	Pkgbase string   `json:"pkgbase"`
+	URLs    []string `json:"urls"`
+	Version string   `json:"version"`
+}
+
+// PluginProviderName is the name of every plugin provider, regardless of the plugin.
+const PluginProviderName = "plugin"
+
+// pluginProvider runs an external executable, which outputs JSON list of candidate versions of the package.
+type pluginProvider struct {
+	rawVersionRecorder
+	executable    string
+	pkg           PluginPackage
+	commandRunner EnvCommandRunner
+}
+
+// NewPluginProvider creates provider running the plugin with the given name for the given package.
+// Plugin executable is taken from 'plugins.<name>.command' of the providers config, or found by its name
+// in the plugins directory. Returns error if there's no such plugin.
+func (factory *ProviderFactory) NewPluginProvider(pluginName string, providersConfig config.Value, pkg PluginPackage) (VersionProvider, error) {
+	executable, err := factory.pluginExecutable(pluginName, providersConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &pluginProvider{executable: executable, pkg: pkg, commandRunner: factory.envCommandRunner}, nil
+}
+
+func (factory *ProviderFactory) pluginExecutable(pluginName string, providersConfig config.Value) (string, error) {
+	var command string
+	providersConfig.Get("plugins").Get(pluginName).Get("command").Populate(&command) //nolint:errcheck
+	if command != "" {
+		return command, nil
+	}
+
+	if factory.pluginsDir != "" && pluginName == filepath.Base(pluginName) {
+		executable := filepath.Join(factory.pluginsDir, pluginName)
+		if fileInfo, err := os.Stat(executable); err == nil && fileInfo.Mode().IsRegular() && fileInfo.Mode()&0o111 != 0 {
+			return executable, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: unknown plugin '%s'", ErrInvalidPackageConfig, pluginName)
+}
+
+func (plugin *pluginProvider) Equal(other interface{}) bool {
+	switch other := other.(type) {
+	case *pluginProvider:
+		return plugin.executable == other.executable && plugin.pkg.Pkgbase == other.pkg.Pkgbase
+	default:
+		return false
+	}
+}
+
+// LatestVersion runs the plugin and returns the highest of the versions it outputs.
+// Package is described in the environment variables: BUMPER_PKGBASE, BUMPER_VERSION,
+// BUMPER_URLS (newline separated) and BUMPER_PACKAGE (all of the above, JSON encoded).
+func (plugin *pluginProvider) LatestVersion() (Version, error) {
+	encodedPkg, err := json.Marshal(plugin.pkg)
+	if err != nil {
+		return "", fmt.Errorf("%w: plugin %s: %w", ErrProviderError, plugin.executable, err)
+	}
+
+	env := []string{
+		"BUMPER_PKGBASE=" + plugin.pkg.Pkgbase,
+		"BUMPER_VERSION=" + plugin.pkg.Version,
+		"BUMPER_URLS=" + strings.Join(plugin.pkg.URLs, "\n"),
+		"BUMPER_PACKAGE=" + string(encodedPkg),
+	}
+	stdout, err := plugin.commandRunner("", env, plugin.executable)
+	if err != nil {
+		return "", fmt.Errorf("%w: plugin %s: %w", ErrProviderError, plugin.executable, err)
+	}
+
+	var candidates []string
+	if err := json.Unmarshal(stdout, &candidates); err != nil {
+		return "", fmt.Errorf("%w: plugin %s: invalid output: %w", ErrProviderError, plugin.executable, err)
+	}
+
+	var highestVersion Version
+	var highestRawVersion string
+	for _, candidate := range candidates {
+		version, isValid := ParseVersion(candidate)
+		if isValid && (highestVersion == "" || vercmp.Rpmvercmp(string(version), string(highestVersion)) > 0) {
+			highestVersion = version
+			highestRawVersion = candidate
+		}
+	}
+
+	if highestVersion == "" {
+		return "", ErrVersionNotFound
+	}
+	plugin.latestRawVersion = highestRawVersion
+	return highestVersion, nil
+}
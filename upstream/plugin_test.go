@@ -0,0 +1,96 @@
+package upstream
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bcyran/bumper/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testPluginPackage = PluginPackage{Pkgbase: "foo", URLs: []string{"https://foo.example.com", "https://foo.example.com/foo-1.0.0.tar.gz"}, Version: "1.0.0"}
+
+func TestNewPluginProvider_Configured(t *testing.T) {
+	factory := NewProviderFactory(testHTTPClient, nil, "")
+	providersConfig := testConfig(t, "{test: {plugins: {portal: {command: /opt/portal-versions}}}}")
+
+	provider, err := factory.NewPluginProvider("portal", providersConfig, testPluginPackage)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &pluginProvider{executable: "/opt/portal-versions", pkg: testPluginPackage}, provider)
+}
+
+func TestNewPluginProvider_Discovered(t *testing.T) {
+	pluginsDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(pluginsDir, "portal"), []byte("#!/bin/sh\n"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(pluginsDir, "not-executable"), []byte("#!/bin/sh\n"), 0o644))
+	factory := NewProviderFactory(testHTTPClient, nil, pluginsDir)
+	emptyConfig := testConfig(t, "{}")
+
+	provider, err := factory.NewPluginProvider("portal", emptyConfig, testPluginPackage)
+	assert.NoError(t, err)
+	assert.Equal(t, &pluginProvider{executable: filepath.Join(pluginsDir, "portal"), pkg: testPluginPackage}, provider)
+
+	for _, invalidName := range []string{"not-executable", "missing", "../portal"} {
+		_, err = factory.NewPluginProvider(invalidName, emptyConfig, testPluginPackage)
+		assert.ErrorIs(t, err, ErrInvalidPackageConfig, invalidName)
+	}
+}
+
+func TestPluginLatestVersion(t *testing.T) {
+	commandRetvals := []testutils.CommandRunnerRetval{
+		{Stdout: []byte(`["1.9.0", "1.10.0", "2.0.0-beta", "whatever"]`), Err: nil},
+	}
+	_, fakeRunner, commandRuns := testutils.MakeFakeCommandRunners(&commandRetvals)
+	plugin := pluginProvider{executable: "/opt/portal-versions", pkg: testPluginPackage, commandRunner: fakeRunner}
+
+	result, err := plugin.LatestVersion()
+
+	assert.NoError(t, err)
+	assert.Equal(t, Version("1.10.0"), result)
+	expectedEnv := []string{
+		"BUMPER_PKGBASE=foo",
+		"BUMPER_VERSION=1.0.0",
+		"BUMPER_URLS=https://foo.example.com\nhttps://foo.example.com/foo-1.0.0.tar.gz",
+		`BUMPER_PACKAGE={"pkgbase":"foo","urls":["https://foo.example.com","https://foo.example.com/foo-1.0.0.tar.gz"],"version":"1.0.0"}`,
+	}
+	assert.Equal(t, []testutils.CommandRunnerParams{{Cwd: "", Env: expectedEnv, Command: "/opt/portal-versions"}}, *commandRuns)
+}
+
+func TestPluginLatestVersion_NoVersions(t *testing.T) {
+	commandRetvals := []testutils.CommandRunnerRetval{{Stdout: []byte(`[]`), Err: nil}}
+	_, fakeRunner, _ := testutils.MakeFakeCommandRunners(&commandRetvals)
+	plugin := pluginProvider{executable: "/opt/portal-versions", pkg: testPluginPackage, commandRunner: fakeRunner}
+
+	_, err := plugin.LatestVersion()
+
+	assert.ErrorIs(t, err, ErrVersionNotFound)
+}
+
+func TestPluginLatestVersion_Failed(t *testing.T) {
+	cases := []testutils.CommandRunnerRetval{
+		{Stdout: []byte{}, Err: errors.New("exit status 1")},
+		{Stdout: []byte("1.2.3"), Err: nil},
+	}
+
+	for _, retval := range cases {
+		commandRetvals := []testutils.CommandRunnerRetval{retval}
+		_, fakeRunner, _ := testutils.MakeFakeCommandRunners(&commandRetvals)
+		plugin := pluginProvider{executable: "/opt/portal-versions", pkg: testPluginPackage, commandRunner: fakeRunner}
+
+		_, err := plugin.LatestVersion()
+
+		assert.ErrorIs(t, err, ErrProviderError)
+	}
+}
+
+func TestPluginEqual(t *testing.T) {
+	plugin := &pluginProvider{executable: "/opt/portal-versions", pkg: PluginPackage{Pkgbase: "foo"}}
+
+	assert.True(t, plugin.Equal(&pluginProvider{executable: "/opt/portal-versions", pkg: PluginPackage{Pkgbase: "foo"}}))
+	assert.False(t, plugin.Equal(&pluginProvider{executable: "/opt/other", pkg: PluginPackage{Pkgbase: "foo"}}))
+	assert.False(t, plugin.Equal(&pypiProvider{packageName: "foo"}))
+}
@@ -1,6 +1,9 @@
 package upstream
 
 import (
+	"fmt"
+	"slices"
+
 	"go.uber.org/config"
 )
 
@@ -10,17 +13,212 @@ type VersionProvider interface {
 	Equal(other interface{}) bool
 }
 
-// NewVersionProvider tries to create a VersionProvider instance for a given URL.
-// Returns nil if there's no suitable provider.
-func NewVersionProvider(url string, providersConfig config.Value) VersionProvider {
-	if pypiProvider := newPypiProvider(url); pypiProvider != nil {
-		return pypiProvider
+// Revision identifies the source of a version in its upstream repo.
+type Revision struct {
+	Tag    string
+	Commit string
+}
+
+// RevisionProvider is a VersionProvider which can also tell the tag and the commit of the version it found.
+type RevisionProvider interface {
+	VersionProvider
+	// LatestRevision returns revision of the version found by the last successful LatestVersion call.
+	LatestRevision() (Revision, error)
+}
+
+// ReleaseNotesProvider is a VersionProvider which can also return notes of the release it found.
+type ReleaseNotesProvider interface {
+	VersionProvider
+	// LatestReleaseNotes returns notes of the release found by the last successful LatestVersion call.
+	// It's empty if the version was found in tags, or the release has no notes.
+	LatestReleaseNotes() string
+}
+
+// RawVersionProvider is a VersionProvider which can also return the upstream version string it found,
+// before it was normalised to Version, e.g. with mangle rules or 'v' prefix removal.
+type RawVersionProvider interface {
+	VersionProvider
+	// LatestRawVersion returns upstream version string of the version found by the last successful LatestVersion call.
+	LatestRawVersion() string
+}
+
+// ComparingProvider is a VersionProvider whose versions don't compare correctly with rpmvercmp,
+// e.g. CPAN decimal versions.
+type ComparingProvider interface {
+	VersionProvider
+	// CompareVersions compares version a found by the provider with version b, the same way as rpmvercmp.
+	CompareVersions(a string, b string) int
+}
+
+// providerContext holds everything, apart from the URL, a provider might need to be created.
+type providerContext struct {
+	// providerConfig is the config section of the specific provider, e.g. check.providers.github
+	providerConfig config.Value
+	// packageConfig is the config section of the package, e.g. check.packages.foo
+	packageConfig config.Value
+	filter        versionFilter
+	// watch is the watch line configured for the package, nil if there's none
+	watch      *watchLine
+	httpClient *HTTPClient
+}
+
+// providerConstructor creates a provider for the given URL, or returns nil if the URL is not supported.
+type providerConstructor func(url string, providerCtx *providerContext) VersionProvider
+
+// providerConstructors maps provider names, as used in the config, to their constructors.
+var providerConstructors = map[string]providerConstructor{
+	"archrepo": func(_url string, providerCtx *providerContext) VersionProvider {
+		return asProvider(newArchRepoProvider(providerCtx.providerConfig, providerCtx.packageConfig, providerCtx.httpClient))
+	},
+	"aur": func(_url string, providerCtx *providerContext) VersionProvider {
+		return asProvider(newAURProvider(providerCtx.providerConfig, providerCtx.packageConfig, providerCtx.httpClient))
+	},
+	"watch": func(_url string, providerCtx *providerContext) VersionProvider {
+		return asProvider(newWatchProvider(providerCtx.watch, providerCtx.httpClient))
+	},
+	"pypi": func(url string, providerCtx *providerContext) VersionProvider {
+		return asProvider(newPypiProvider(url, providerCtx.httpClient))
+	},
+	"rubygems": func(url string, providerCtx *providerContext) VersionProvider {
+		return asProvider(newRubyGemsProvider(url, providerCtx.httpClient))
+	},
+	"goproxy": func(url string, providerCtx *providerContext) VersionProvider {
+		return asProvider(newGoProxyProvider(url, providerCtx.providerConfig, providerCtx.packageConfig, providerCtx.httpClient))
+	},
+	"metacpan": func(url string, providerCtx *providerContext) VersionProvider {
+		return asProvider(newMetaCPANProvider(url, providerCtx.providerConfig, providerCtx.packageConfig, providerCtx.httpClient))
+	},
+	"hackage": func(url string, providerCtx *providerContext) VersionProvider {
+		return asProvider(newHackageProvider(url, providerCtx.providerConfig, providerCtx.httpClient))
+	},
+	"sourceforge": func(url string, providerCtx *providerContext) VersionProvider {
+		return asProvider(newSourceForgeProvider(url, providerCtx.providerConfig, providerCtx.filter, providerCtx.httpClient))
+	},
+	"packagist": func(url string, providerCtx *providerContext) VersionProvider {
+		return asProvider(newPackagistProvider(url, providerCtx.providerConfig, providerCtx.httpClient))
+	},
+	"maven": func(url string, providerCtx *providerContext) VersionProvider {
+		return asProvider(newMavenProvider(url, providerCtx.providerConfig, providerCtx.httpClient))
+	},
+	"github": func(url string, providerCtx *providerContext) VersionProvider {
+		return asProvider(newGitHubProvider(url, providerCtx.providerConfig, providerCtx.filter, providerCtx.httpClient))
+	},
+	"bitbucket": func(url string, providerCtx *providerContext) VersionProvider {
+		return asProvider(newBitbucketProvider(url, providerCtx.providerConfig, providerCtx.filter, providerCtx.httpClient))
+	},
+	"gitlab": func(url string, providerCtx *providerContext) VersionProvider {
+		return asProvider(newGitLabProvider(url, providerCtx.providerConfig, providerCtx.filter, providerCtx.httpClient))
+	},
+}
+
+// defaultProviderPriority is the order in which the providers are tried, unless configured otherwise.
+// Providers following other packages and watch lines come first, they don't depend on the URL
+// and are used only if configured.
+// GitLab is the last one, because its URL matching is the least strict, e.g. it matches bitbucket.org too.
+var defaultProviderPriority = []string{
+	"archrepo", "aur", "watch",
+	"pypi", "rubygems", "goproxy", "metacpan", "hackage", "sourceforge", "packagist", "maven",
+	"github", "bitbucket", "gitlab",
+}
+
+// asProvider converts a provider pointer to the VersionProvider interface.
+// Nil pointer is converted to nil interface, instead of non-nil interface holding nil pointer.
+func asProvider[T any, P interface {
+	*T
+	VersionProvider
+}](provider P) VersionProvider {
+	if provider == nil {
+		return nil
 	}
-	if gitHubProvider := newGitHubProvider(url, providersConfig.Get("github")); gitHubProvider != nil {
-		return gitHubProvider
+	return provider
+}
+
+// ProviderFactory creates VersionProviders sharing the same HTTPClient and EnvCommandRunner.
+type ProviderFactory struct {
+	httpClient       *HTTPClient
+	envCommandRunner EnvCommandRunner
+	// pluginsDir is searched for plugin executables, plugins can only be configured explicitly if it's empty
+	pluginsDir string
+}
+
+func NewProviderFactory(httpClient *HTTPClient, envCommandRunner EnvCommandRunner, pluginsDir string) *ProviderFactory {
+	return &ProviderFactory{httpClient: httpClient, envCommandRunner: envCommandRunner, pluginsDir: pluginsDir}
+}
+
+// NewVersionProvider tries to create a VersionProvider instance for a given URL of the package with the given pkgbase.
+// Package config holds settings specific to the package, e.g. tag pattern or explicitly selected provider.
+// If the provider is not selected explicitly, the providers are tried in the order configured in
+// 'priority' list of the providers config, followed by the remaining providers in the default order.
+// Returns the provider along with its name, as used in the config.
+// Returns nil if there's no suitable provider, error if the config is invalid.
+func (factory *ProviderFactory) NewVersionProvider(url string, pkgbase string, providersConfig config.Value, packageConfig config.Value) (VersionProvider, string, error) {
+	filter, err := newVersionFilter(packageConfig)
+	if err != nil {
+		return nil, "", err
+	}
+	watch, err := newWatchLine(pkgbase, packageConfig)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var providerNames []string
+	var selectedProvider string
+	packageConfig.Get("provider").Populate(&selectedProvider) //nolint:errcheck
+	if selectedProvider != "" {
+		if _, isKnown := providerConstructors[selectedProvider]; !isKnown {
+			return nil, "", fmt.Errorf("%w: unknown provider '%s'", ErrInvalidPackageConfig, selectedProvider)
+		}
+		providerNames = []string{selectedProvider}
+	} else {
+		if providerNames, err = providerPriority(providersConfig); err != nil {
+			return nil, "", err
+		}
+	}
+
+	for _, providerName := range providerNames {
+		providerCtx := &providerContext{
+			providerConfig: providersConfig.Get(providerName),
+			packageConfig:  packageConfig,
+			filter:         filter,
+			watch:          watch,
+			httpClient:     factory.httpClient,
+		}
+		if provider := providerConstructors[providerName](url, providerCtx); provider != nil {
+			return provider, providerName, nil
+		}
 	}
-	if gitLabProvider := newGitLabProvider(url, providersConfig.Get("gitlab")); gitLabProvider != nil { // nolint:revive
-		return gitLabProvider
+	return nil, "", nil
+}
+
+// providerPriority returns names of all the providers in the order in which they should be tried.
+func providerPriority(providersConfig config.Value) ([]string, error) {
+	var configuredPriority []string
+	if err := providersConfig.Get("priority").Populate(&configuredPriority); err != nil {
+		return nil, fmt.Errorf("%w: invalid provider priority: %w", ErrInvalidProvidersConfig, err)
+	}
+
+	priority := make([]string, 0, len(defaultProviderPriority))
+	for _, providerName := range configuredPriority {
+		if _, isKnown := providerConstructors[providerName]; !isKnown {
+			return nil, fmt.Errorf("%w: unknown provider '%s' in priority", ErrInvalidProvidersConfig, providerName)
+		}
+		if !slices.Contains(priority, providerName) {
+			priority = append(priority, providerName)
+		}
 	}
-	return nil
+	for _, providerName := range defaultProviderPriority {
+		if !slices.Contains(priority, providerName) {
+			priority = append(priority, providerName)
+		}
+	}
+
+	return priority, nil
+}
+
+// PrefetchVersions fetches the latest versions for the given providers in batches, where it's supported.
+// Subsequent LatestVersion calls on these providers use the prefetched data instead of querying upstream.
+// Other providers are left untouched. Errors are returned for information only, the providers
+// which could not be prefetched still work as usual.
+func PrefetchVersions(providers []VersionProvider) error {
+	return prefetchGitHub(providers)
 }
@@ -0,0 +1,115 @@
+package upstream
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	// matches both GitHub and GitLab (the latter is very permissive)
+	gitHubURL = "https://github.com/foo/bar"
+	pypiURL   = "https://files.pythonhosted.org/packages/source/f/foo/foo-1.1.0.tar.gz"
+)
+
+func TestNewVersionProvider_DefaultPriority(t *testing.T) {
+	factory := NewProviderFactory(testHTTPClient, nil, "")
+	emptyConfig := testConfig(t, "{}")
+
+	provider, name, err := factory.NewVersionProvider(gitHubURL, "foo", emptyConfig, emptyConfig)
+	assert.NoError(t, err)
+	assert.IsType(t, &gitHubProvider{}, provider)
+	assert.Equal(t, "github", name)
+
+	provider, name, err = factory.NewVersionProvider(pypiURL, "foo", emptyConfig, emptyConfig)
+	assert.NoError(t, err)
+	assert.IsType(t, &pypiProvider{}, provider)
+	assert.Equal(t, "pypi", name)
+
+	// bitbucket.org would match GitLab too
+	provider, name, err = factory.NewVersionProvider("https://bitbucket.org/foo/bar/get/1.0.0.tar.gz", "foo", emptyConfig, emptyConfig)
+	assert.NoError(t, err)
+	assert.IsType(t, &bitbucketProvider{}, provider)
+	assert.Equal(t, "bitbucket", name)
+
+	provider, name, err = factory.NewVersionProvider("https://foo.bar/baz.tar.gz", "foo", emptyConfig, emptyConfig)
+	assert.NoError(t, err)
+	assert.Nil(t, provider)
+	assert.Empty(t, name)
+}
+
+func TestNewVersionProvider_ConfiguredPriority(t *testing.T) {
+	factory := NewProviderFactory(testHTTPClient, nil, "")
+	providersConfig := testConfig(t, "{test: {priority: [gitlab]}}")
+
+	provider, name, err := factory.NewVersionProvider(gitHubURL, "foo", providersConfig, testConfig(t, "{}"))
+
+	assert.NoError(t, err)
+	assert.IsType(t, &gitLabProvider{}, provider)
+	assert.Equal(t, "gitlab", name)
+}
+
+func TestNewVersionProvider_InvalidPriority(t *testing.T) {
+	factory := NewProviderFactory(testHTTPClient, nil, "")
+	providersConfig := testConfig(t, "{test: {priority: [github, foo]}}")
+
+	_, _, err := factory.NewVersionProvider(gitHubURL, "foo", providersConfig, testConfig(t, "{}"))
+
+	assert.ErrorIs(t, err, ErrInvalidProvidersConfig)
+	assert.ErrorContains(t, err, "unknown provider 'foo'")
+}
+
+func TestNewVersionProvider_SelectedProvider(t *testing.T) {
+	factory := NewProviderFactory(testHTTPClient, nil, "")
+	emptyConfig := testConfig(t, "{}")
+
+	provider, name, err := factory.NewVersionProvider(gitHubURL, "foo", emptyConfig, testConfig(t, "{test: {provider: gitlab}}"))
+	assert.NoError(t, err)
+	assert.IsType(t, &gitLabProvider{}, provider)
+	assert.Equal(t, "gitlab", name)
+
+	// selected provider doesn't support the URL, other providers are not tried
+	provider, name, err = factory.NewVersionProvider(gitHubURL, "foo", emptyConfig, testConfig(t, "{test: {provider: pypi}}"))
+	assert.NoError(t, err)
+	assert.Nil(t, provider)
+	assert.Empty(t, name)
+}
+
+func TestNewVersionProvider_InvalidSelectedProvider(t *testing.T) {
+	factory := NewProviderFactory(testHTTPClient, nil, "")
+
+	_, _, err := factory.NewVersionProvider(gitHubURL, "foo", testConfig(t, "{}"), testConfig(t, "{test: {provider: foo}}"))
+
+	assert.ErrorIs(t, err, ErrInvalidPackageConfig)
+	assert.ErrorContains(t, err, "unknown provider 'foo'")
+}
+
+func TestProviderPriority(t *testing.T) {
+	priority, err := providerPriority(testConfig(t, "{test: {priority: [gitlab, pypi, gitlab]}}"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"gitlab", "pypi", "archrepo", "aur", "watch", "rubygems", "goproxy", "metacpan", "hackage", "sourceforge", "packagist", "maven", "github", "bitbucket"}, priority)
+}
+
+func TestNewVersionProvider_Follow(t *testing.T) {
+	factory := NewProviderFactory(testHTTPClient, nil, "")
+
+	provider, name, err := factory.NewVersionProvider(gitHubURL, "foo", testConfig(t, "{}"), testConfig(t, "{test: {follow: {aur: foo}}}"))
+
+	assert.NoError(t, err)
+	assert.IsType(t, &aurProvider{}, provider)
+	assert.Equal(t, "aur", name)
+}
+
+func TestNewVersionProvider_Watch(t *testing.T) {
+	factory := NewProviderFactory(testHTTPClient, nil, "")
+	emptyConfig := testConfig(t, "{}")
+
+	provider, name, err := factory.NewVersionProvider(gitHubURL, "foo", emptyConfig, testConfig(t, `{test: {watch: 'https://foo.bar/ foo-(.+)\.tgz'}}`))
+	assert.NoError(t, err)
+	assert.IsType(t, &watchProvider{}, provider)
+	assert.Equal(t, "watch", name)
+
+	_, _, err = factory.NewVersionProvider(gitHubURL, "foo", emptyConfig, testConfig(t, `{test: {watch: 'https://foo.bar/ foo-(.+'}}`))
+	assert.ErrorIs(t, err, ErrInvalidPackageConfig)
+}
@@ -5,10 +5,15 @@ import (
 	"regexp"
 )
 
-var pypiPackageRegex = regexp.MustCompile(`(files\.pythonhosted\.org|pypi\.python.org|pypi\.org|pypi\.io)/packages/source/[a-z]{1}/([^/#?]+)/`)
+var (
+	pypiPackageRegex = regexp.MustCompile(`(files\.pythonhosted\.org|pypi\.python.org|pypi\.org|pypi\.io)/packages/source/[a-z]{1}/([^/#?]+)/`)
+	pypiProjectRegex = regexp.MustCompile(`(pypi\.python.org|pypi\.org)/(project|pypi)/([^/#?]+)`)
+)
 
 type pypiProvider struct {
+	rawVersionRecorder
 	packageName string
+	httpClient  *HTTPClient
 }
 
 type pypiPackageResp struct {
@@ -17,12 +22,14 @@ type pypiPackageResp struct {
 	} `json:"info"`
 }
 
-func newPypiProvider(url string) *pypiProvider {
-	match := pypiPackageRegex.FindStringSubmatch(url)
-	if len(match) == 0 {
-		return nil
+func newPypiProvider(url string, httpClient *HTTPClient) *pypiProvider {
+	if match := pypiPackageRegex.FindStringSubmatch(url); len(match) != 0 {
+		return &pypiProvider{packageName: match[2], httpClient: httpClient}
+	}
+	if match := pypiProjectRegex.FindStringSubmatch(url); len(match) != 0 {
+		return &pypiProvider{packageName: match[3], httpClient: httpClient}
 	}
-	return &pypiProvider{packageName: match[2]}
+	return nil
 }
 
 func (pypi *pypiProvider) packageInfoURL() string {
@@ -40,10 +47,10 @@ func (pypi *pypiProvider) Equal(other interface{}) bool {
 
 func (pypi *pypiProvider) LatestVersion() (Version, error) {
 	var packageInfo pypiPackageResp
-	if err := httpGetJSON(pypi.packageInfoURL(), &packageInfo, nil); err != nil {
+	if err := pypi.httpClient.getJSON(pypi.packageInfoURL(), &packageInfo, nil); err != nil {
 		return "", err
 	}
-	if version, isValid := ParseVersion(packageInfo.Info.Version); isValid {
+	if version, isValid := pypi.parseRawVersion(packageInfo.Info.Version); isValid {
 		return version, nil
 	}
 	return "", ErrVersionNotFound
@@ -13,10 +13,13 @@ func TestNewPypi_Valid(t *testing.T) {
 		"https://pypi.python.org/packages/source/b/bar/bar-1.1.0.tar.gz":        {packageName: "bar"},
 		"https://pypi.org/packages/source/b/baz/baz-1.1.0.tar.gz":               {packageName: "baz"},
 		"https://pypi.io/packages/source/f/foo/foo-1.1.0.tar.gz":                {packageName: "foo"},
+		"https://pypi.org/project/foo-bar/":                                     {packageName: "foo-bar"},
+		"https://pypi.python.org/pypi/baz":                                      {packageName: "baz"},
 	}
 
 	for validURL, expectedResult := range cases {
-		result := newPypiProvider(validURL)
+		expectedResult.httpClient = testHTTPClient
+		result := newPypiProvider(validURL, testHTTPClient)
 		assert.Equal(t, &expectedResult, result)
 	}
 }
@@ -24,7 +27,7 @@ func TestNewPypi_Valid(t *testing.T) {
 func TestNewPypi_Invalid(t *testing.T) {
 	invalidURL := "https://whatever.url/packages/source/f/foo/foo-1.1.0.tar.gz"
 
-	result := newPypiProvider(invalidURL)
+	result := newPypiProvider(invalidURL, testHTTPClient)
 	assert.Nil(t, result)
 }
 
@@ -42,7 +45,7 @@ func TestPypiLatestVersion(t *testing.T) {
 			},
 		})
 
-	pypi := pypiProvider{packageName: "some-package"}
+	pypi := pypiProvider{packageName: "some-package", httpClient: testHTTPClient}
 
 	result, err := pypi.LatestVersion()
 
@@ -57,7 +60,7 @@ func TestPypiLatestVersion_4xx(t *testing.T) {
 		Reply(404).
 		JSON(map[string]string{"message": "Not Found"})
 
-	pypi := pypiProvider{packageName: "some-package"}
+	pypi := pypiProvider{packageName: "some-package", httpClient: testHTTPClient}
 
 	_, err := pypi.LatestVersion()
 
@@ -65,13 +68,15 @@ func TestPypiLatestVersion_4xx(t *testing.T) {
 }
 
 func TestPypiLatestVersion_5xx(t *testing.T) {
+	httpClient, _ := newFakeClockHTTPClient(t)
 	defer gock.Off()
 	gock.New("https://pypi.org").
 		Get("/pypi/some-package/json").
+		Times(maxRetries + 1).
 		Reply(501).
 		JSON(map[string]string{"message": "Not Found"})
 
-	pypi := pypiProvider{packageName: "some-package"}
+	pypi := pypiProvider{packageName: "some-package", httpClient: httpClient}
 
 	_, err := pypi.LatestVersion()
 
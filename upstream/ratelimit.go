@@ -0,0 +1,117 @@
+package upstream
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxRateLimitWait is the longest we are willing to wait for the rate limit reset before giving up.
+const maxRateLimitWait = time.Minute
+
+var ErrRateLimited = errors.New("rate limited")
+
+// RateLimitError is returned when the upstream API refuses the request because of the rate limiting.
+// It matches ErrRateLimited when checked with errors.Is.
+type RateLimitError struct {
+	URL string
+	// Reset is the time when the rate limit resets, zero if unknown.
+	Reset time.Time
+}
+
+func (err *RateLimitError) Error() string {
+	if err.Reset.IsZero() {
+		return fmt.Sprintf("%s: GET %s", ErrRateLimited, err.URL)
+	}
+	return fmt.Sprintf("%s: GET %s, resets at %s", ErrRateLimited, err.URL, err.Reset.Format(time.TimeOnly))
+}
+
+func (err *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// rateLimit holds the rate limit state as reported by the upstream API response headers.
+type rateLimit struct {
+	// remaining is the number of requests left, -1 if unknown.
+	remaining int
+	// reset is the time when the limit resets, zero if unknown.
+	reset time.Time
+}
+
+// parseRateLimit reads rate limit info from the response headers.
+// Understands both GitHub style 'X-RateLimit-*' and GitLab style 'RateLimit-*' headers, as well as 'Retry-After'.
+func parseRateLimit(header http.Header, now time.Time) rateLimit {
+	limit := rateLimit{remaining: -1}
+
+	for _, remainingHeader := range []string{"X-RateLimit-Remaining", "RateLimit-Remaining"} {
+		if remaining, err := strconv.Atoi(header.Get(remainingHeader)); err == nil {
+			limit.remaining = remaining
+			break
+		}
+	}
+
+	for _, resetHeader := range []string{"X-RateLimit-Reset", "RateLimit-Reset"} {
+		if reset, err := strconv.ParseInt(header.Get(resetHeader), 10, 64); err == nil {
+			limit.reset = time.Unix(reset, 0)
+			break
+		}
+	}
+
+	// Retry-After is more specific than the reset time, if present it takes precedence
+	if retryAfter := header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			limit.reset = now.Add(time.Duration(seconds) * time.Second)
+		} else if date, err := http.ParseTime(retryAfter); err == nil {
+			limit.reset = date
+		}
+	}
+
+	return limit
+}
+
+func (limit rateLimit) isExhausted() bool {
+	return limit.remaining == 0
+}
+
+// isResetReasonable checks whether the reset time is known and close enough to wait for it.
+func (limit rateLimit) isResetReasonable(now time.Time) bool {
+	return !limit.reset.IsZero() && limit.reset.Sub(now) <= maxRateLimitWait
+}
+
+// isRateLimited checks whether the response is a refusal because of the rate limiting.
+// GitHub responds with 403 and exhausted limit, other APIs usually use 429.
+func isRateLimited(resp *http.Response, limit rateLimit) bool {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return resp.StatusCode == http.StatusForbidden && limit.isExhausted()
+}
+
+// hostPauseRegistry keeps track of hosts to which requests should not be sent until specified time.
+type hostPauseRegistry struct {
+	until map[string]time.Time
+	mtx   sync.Mutex
+}
+
+func newHostPauseRegistry() *hostPauseRegistry {
+	return &hostPauseRegistry{until: map[string]time.Time{}}
+}
+
+// pause prevents requests to the host until the given time.
+func (registry *hostPauseRegistry) pause(host string, until time.Time) {
+	registry.mtx.Lock()
+	defer registry.mtx.Unlock()
+	if until.After(registry.until[host]) {
+		registry.until[host] = until
+	}
+}
+
+// pausedUntil returns the time until which requests to the host are paused, zero if not paused.
+func (registry *hostPauseRegistry) pausedUntil(host string) time.Time {
+	registry.mtx.Lock()
+	defer registry.mtx.Unlock()
+	return registry.until[host]
+}
@@ -0,0 +1,216 @@
+package upstream
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+)
+
+var fakeNow = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func TestParseRateLimit(t *testing.T) {
+	reset := fakeNow.Add(30 * time.Second)
+	cases := []struct {
+		header   http.Header
+		expected rateLimit
+	}{
+		{
+			header:   http.Header{},
+			expected: rateLimit{remaining: -1},
+		},
+		{
+			header: http.Header{
+				"X-Ratelimit-Remaining": {"0"},
+				"X-Ratelimit-Reset":     {strconv.FormatInt(reset.Unix(), 10)},
+			},
+			expected: rateLimit{remaining: 0, reset: reset},
+		},
+		{
+			header: http.Header{
+				"Ratelimit-Remaining": {"42"},
+				"Ratelimit-Reset":     {strconv.FormatInt(reset.Unix(), 10)},
+			},
+			expected: rateLimit{remaining: 42, reset: reset},
+		},
+		{
+			header:   http.Header{"Retry-After": {"30"}},
+			expected: rateLimit{remaining: -1, reset: reset},
+		},
+		{
+			header:   http.Header{"Retry-After": {reset.Format(http.TimeFormat)}},
+			expected: rateLimit{remaining: -1, reset: reset},
+		},
+	}
+
+	for _, testCase := range cases {
+		result := parseRateLimit(testCase.header, fakeNow)
+		assert.Equal(t, testCase.expected.remaining, result.remaining)
+		assert.True(t, testCase.expected.reset.Equal(result.reset), "expected %v, got %v", testCase.expected.reset, result.reset)
+	}
+}
+
+func TestHTTPClientGetJSON_Retries5xx(t *testing.T) {
+	client, sleeps := newFakeClockHTTPClient(t)
+	defer gock.Off()
+	gock.New("https://foo.bar").
+		Get("/baz").
+		Times(2).
+		Reply(502)
+	gock.New("https://foo.bar").
+		Get("/baz").
+		Reply(200).
+		JSON(map[string]string{"foo": "bar"})
+
+	var result map[string]string
+	err := client.getJSON("https://foo.bar/baz", &result, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"foo": "bar"}, result)
+	assert.Equal(t, []time.Duration{retryBaseDelay, 2 * retryBaseDelay}, *sleeps)
+}
+
+func TestHTTPClientGetJSON_Retries5xxBudgetExceeded(t *testing.T) {
+	client, sleeps := newFakeClockHTTPClient(t)
+	defer gock.Off()
+	gock.New("https://foo.bar").
+		Get("/baz").
+		Times(maxRetries + 1).
+		Reply(500)
+
+	var result map[string]string
+	err := client.getJSON("https://foo.bar/baz", &result, nil)
+
+	assert.ErrorIs(t, err, ErrProviderError)
+	assert.Len(t, *sleeps, maxRetries)
+	assert.True(t, gock.IsDone())
+}
+
+func TestHTTPClientGetJSON_WaitsForRateLimitReset(t *testing.T) {
+	client, sleeps := newFakeClockHTTPClient(t)
+	defer gock.Off()
+	gock.New("https://foo.bar").
+		Get("/baz").
+		Reply(403).
+		SetHeader("X-RateLimit-Remaining", "0").
+		SetHeader("X-RateLimit-Reset", strconv.FormatInt(fakeNow.Add(20*time.Second).Unix(), 10))
+	gock.New("https://foo.bar").
+		Get("/baz").
+		Reply(200).
+		JSON(map[string]string{"foo": "bar"})
+
+	var result map[string]string
+	err := client.getJSON("https://foo.bar/baz", &result, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"foo": "bar"}, result)
+	assert.Equal(t, []time.Duration{20 * time.Second}, *sleeps)
+}
+
+func TestHTTPClientGetJSON_PausesHostWhenLimitExhausted(t *testing.T) {
+	client, sleeps := newFakeClockHTTPClient(t)
+	defer gock.Off()
+	gock.New("https://foo.bar").
+		Get("/first").
+		Reply(200).
+		SetHeader("X-RateLimit-Remaining", "0").
+		SetHeader("X-RateLimit-Reset", strconv.FormatInt(fakeNow.Add(10*time.Second).Unix(), 10)).
+		JSON(map[string]string{})
+	gock.New("https://foo.bar").
+		Get("/second").
+		Reply(200).
+		JSON(map[string]string{})
+	gock.New("https://other.host").
+		Get("/third").
+		Reply(200).
+		JSON(map[string]string{})
+
+	var result map[string]string
+	assert.NoError(t, client.getJSON("https://other.host/third", &result, nil))
+	assert.NoError(t, client.getJSON("https://foo.bar/first", &result, nil))
+	assert.Empty(t, *sleeps)
+	assert.NoError(t, client.getJSON("https://foo.bar/second", &result, nil))
+	assert.Equal(t, []time.Duration{10 * time.Second}, *sleeps)
+}
+
+func TestHTTPClientGetJSON_RateLimitedRetryAfter(t *testing.T) {
+	client, sleeps := newFakeClockHTTPClient(t)
+	defer gock.Off()
+	gock.New("https://foo.bar").
+		Get("/baz").
+		Reply(429).
+		SetHeader("Retry-After", "5")
+	gock.New("https://foo.bar").
+		Get("/baz").
+		Reply(200).
+		JSON(map[string]string{"foo": "bar"})
+
+	var result map[string]string
+	err := client.getJSON("https://foo.bar/baz", &result, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Duration{5 * time.Second}, *sleeps)
+}
+
+func TestHTTPClientGetJSON_RateLimitedResetTooFar(t *testing.T) {
+	client, sleeps := newFakeClockHTTPClient(t)
+	reset := fakeNow.Add(time.Hour)
+	defer gock.Off()
+	gock.New("https://api.github.com").
+		Get("/foo").
+		Reply(403).
+		SetHeader("X-RateLimit-Remaining", "0").
+		SetHeader("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+
+	var result map[string]string
+	err := client.getJSON("https://api.github.com/foo", &result, nil)
+
+	assert.ErrorIs(t, err, ErrRateLimited)
+	var rateLimitErr *RateLimitError
+	assert.ErrorAs(t, err, &rateLimitErr)
+	assert.True(t, reset.Equal(rateLimitErr.Reset))
+	assert.Empty(t, *sleeps)
+}
+
+func TestHTTPClientGetJSON_RateLimitedBudgetExceeded(t *testing.T) {
+	client, sleeps := newFakeClockHTTPClient(t)
+	defer gock.Off()
+	gock.New("https://foo.bar").
+		Get("/baz").
+		Times(maxRetries + 1).
+		Reply(429)
+
+	var result map[string]string
+	err := client.getJSON("https://foo.bar/baz", &result, nil)
+
+	assert.ErrorIs(t, err, ErrRateLimited)
+	assert.Len(t, *sleeps, maxRetries)
+	assert.True(t, gock.IsDone())
+}
+
+func TestHTTPClientGetJSON_ForbiddenNotRateLimited(t *testing.T) {
+	client, _ := newFakeClockHTTPClient(t)
+	defer gock.Off()
+	gock.New("https://foo.bar").
+		Get("/baz").
+		Reply(403).
+		SetHeader("X-RateLimit-Remaining", "10")
+
+	var result map[string]string
+	err := client.getJSON("https://foo.bar/baz", &result, nil)
+
+	assert.ErrorIs(t, err, ErrVersionNotFound)
+	assert.NotErrorIs(t, err, ErrRateLimited)
+}
+
+func TestRateLimitError_String(t *testing.T) {
+	assert.Equal(
+		t,
+		"rate limited: GET https://foo.bar, resets at 12:00:00",
+		(&RateLimitError{URL: "https://foo.bar", Reset: fakeNow}).Error(),
+	)
+	assert.Equal(t, "rate limited: GET https://foo.bar", (&RateLimitError{URL: "https://foo.bar"}).Error())
+}
@@ -0,0 +1,65 @@
+package upstream
+
+import (
+	"fmt"
+	"regexp"
+)
+
+var (
+	// gem name may contain hyphen followed by a digit too, so the version starts at the last one
+	rubyGemsDownloadRegex = regexp.MustCompile(`rubygems\.org/downloads/([^/#?]+)-\d[^/#?]*\.gem`)
+	rubyGemsGemRegex      = regexp.MustCompile(`rubygems\.org/gems/([^/#?]+)`)
+)
+
+type rubyGemsProvider struct {
+	rawVersionRecorder
+	gemName    string
+	httpClient *HTTPClient
+}
+
+type rubyGemsVersionResp struct {
+	Number     string `json:"number"`
+	Prerelease bool   `json:"prerelease"`
+}
+
+func newRubyGemsProvider(url string, httpClient *HTTPClient) *rubyGemsProvider {
+	if match := rubyGemsDownloadRegex.FindStringSubmatch(url); len(match) != 0 {
+		return &rubyGemsProvider{gemName: match[1], httpClient: httpClient}
+	}
+	if match := rubyGemsGemRegex.FindStringSubmatch(url); len(match) != 0 {
+		return &rubyGemsProvider{gemName: match[1], httpClient: httpClient}
+	}
+	return nil
+}
+
+func (rubyGems *rubyGemsProvider) versionsURL() string {
+	return fmt.Sprintf("https://rubygems.org/api/v1/versions/%s.json", rubyGems.gemName)
+}
+
+func (rubyGems *rubyGemsProvider) Equal(other interface{}) bool {
+	switch other := other.(type) {
+	case *rubyGemsProvider:
+		return rubyGems.gemName == other.gemName
+	default:
+		return false
+	}
+}
+
+func (rubyGems *rubyGemsProvider) LatestVersion() (Version, error) {
+	var versions []rubyGemsVersionResp
+	if err := rubyGems.httpClient.getJSON(rubyGems.versionsURL(), &versions, nil); err != nil {
+		return "", err
+	}
+
+	// Versions are listed from the newest
+	for _, version := range versions {
+		if version.Prerelease {
+			continue
+		}
+		if version, isValid := rubyGems.parseRawVersion(version.Number); isValid {
+			return version, nil
+		}
+	}
+
+	return "", ErrVersionNotFound
+}
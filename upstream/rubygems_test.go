@@ -0,0 +1,89 @@
+package upstream
+
+import (
+	"testing"
+
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRubyGems_Valid(t *testing.T) {
+	cases := map[string]rubyGemsProvider{
+		"https://rubygems.org/downloads/foo-1.1.0.gem":              {gemName: "foo"},
+		"https://rubygems.org/downloads/foo-bar_baz-1.1.0.rc1.gem":  {gemName: "foo-bar_baz"},
+		"https://rubygems.org/downloads/foo-2fa-1.0.0.gem":          {gemName: "foo-2fa"},
+		"https://rubygems.org/downloads/foo-1.0.0-x86_64-linux.gem": {gemName: "foo"},
+		"https://rubygems.org/gems/foo":                             {gemName: "foo"},
+		"https://rubygems.org/gems/foo-bar/versions/1.2.3":          {gemName: "foo-bar"},
+	}
+
+	for validURL, expectedResult := range cases {
+		expectedResult.httpClient = testHTTPClient
+		result := newRubyGemsProvider(validURL, testHTTPClient)
+		assert.Equal(t, &expectedResult, result)
+	}
+}
+
+func TestNewRubyGems_Invalid(t *testing.T) {
+	invalidURL := "https://whatever.url/downloads/foo-1.1.0.gem"
+
+	result := newRubyGemsProvider(invalidURL, testHTTPClient)
+	assert.Nil(t, result)
+}
+
+func TestRubyGemsLatestVersion(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://rubygems.org").
+		Get("/api/v1/versions/some-gem.json").
+		Reply(200).
+		JSON([]map[string]interface{}{
+			{"number": "2.0.0.rc1", "prerelease": true},
+			{"number": "1.2.3", "prerelease": false},
+			{"number": "1.2.2", "prerelease": false},
+		})
+
+	rubyGems := rubyGemsProvider{gemName: "some-gem", httpClient: testHTTPClient}
+
+	result, err := rubyGems.LatestVersion()
+
+	assert.NoError(t, err)
+	assert.Equal(t, Version("1.2.3"), result)
+}
+
+func TestRubyGemsLatestVersion_NoVersions(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://rubygems.org").
+		Get("/api/v1/versions/some-gem.json").
+		Reply(200).
+		JSON([]map[string]interface{}{
+			{"number": "2.0.0.rc1", "prerelease": true},
+		})
+
+	rubyGems := rubyGemsProvider{gemName: "some-gem", httpClient: testHTTPClient}
+
+	_, err := rubyGems.LatestVersion()
+
+	assert.ErrorIs(t, err, ErrVersionNotFound)
+}
+
+func TestRubyGemsLatestVersion_4xx(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://rubygems.org").
+		Get("/api/v1/versions/some-gem.json").
+		Reply(404).
+		BodyString("This rubygem could not be found.")
+
+	rubyGems := rubyGemsProvider{gemName: "some-gem", httpClient: testHTTPClient}
+
+	_, err := rubyGems.LatestVersion()
+
+	assert.ErrorIs(t, err, ErrVersionNotFound)
+}
+
+func TestRubyGemsEqual(t *testing.T) {
+	rubyGems := &rubyGemsProvider{gemName: "foo"}
+
+	assert.True(t, rubyGems.Equal(&rubyGemsProvider{gemName: "foo"}))
+	assert.False(t, rubyGems.Equal(&rubyGemsProvider{gemName: "bar"}))
+	assert.False(t, rubyGems.Equal(&pypiProvider{packageName: "foo"}))
+}
@@ -0,0 +1,110 @@
+package upstream
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.uber.org/config"
+)
+
+const defaultSourceForgeBaseURL = "https://sourceforge.net"
+
+var sourceForgeProjectRegex = regexp.MustCompile(`(downloads\.sourceforge\.net/(project/|sourceforge/)?|sourceforge\.net/projects/)([\w.-]+)`)
+
+type sourceForgeProvider struct {
+	rawVersionRecorder
+	project    string
+	baseURL    string
+	filter     versionFilter
+	httpClient *HTTPClient
+}
+
+type sourceForgeBestReleaseResp struct {
+	Release *struct {
+		Filename string `json:"filename"`
+	} `json:"release"`
+}
+
+type sourceForgeRSSResp struct {
+	Items []struct {
+		Title string `xml:"title"`
+	} `xml:"channel>item"`
+}
+
+func newSourceForgeProvider(url string, sourceForgeConfig config.Value, filter versionFilter, httpClient *HTTPClient) *sourceForgeProvider {
+	match := sourceForgeProjectRegex.FindStringSubmatch(url)
+	if len(match) == 0 {
+		return nil
+	}
+
+	baseURL := defaultSourceForgeBaseURL
+	sourceForgeConfig.Get("baseUrl").Populate(&baseURL) //nolint:errcheck
+
+	return &sourceForgeProvider{
+		project:    match[3],
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		filter:     filter,
+		httpClient: httpClient,
+	}
+}
+
+func (sourceForge *sourceForgeProvider) bestReleaseURL() string {
+	return fmt.Sprintf("%s/projects/%s/best_release.json", sourceForge.baseURL, sourceForge.project)
+}
+
+func (sourceForge *sourceForgeProvider) rssURL() string {
+	return fmt.Sprintf("%s/projects/%s/rss?path=/", sourceForge.baseURL, sourceForge.project)
+}
+
+func (sourceForge *sourceForgeProvider) Equal(other interface{}) bool {
+	switch other := other.(type) {
+	case *sourceForgeProvider:
+		return sourceForge.project == other.project && sourceForge.baseURL == other.baseURL
+	default:
+		return false
+	}
+}
+
+// LatestVersion extracts version from the path of the project's best release file.
+// If it's not suitable, e.g. doesn't match the file pattern, the files RSS feed is checked instead.
+func (sourceForge *sourceForgeProvider) LatestVersion() (Version, error) {
+	version, err := sourceForge.bestReleaseVersion()
+	if err == nil || !errors.Is(err, ErrVersionNotFound) {
+		return version, err
+	}
+	return sourceForge.rssVersion()
+}
+
+func (sourceForge *sourceForgeProvider) bestReleaseVersion() (Version, error) {
+	var bestRelease sourceForgeBestReleaseResp
+	if err := sourceForge.httpClient.getJSON(sourceForge.bestReleaseURL(), &bestRelease, nil); err != nil {
+		return "", err
+	}
+	if bestRelease.Release == nil {
+		return "", ErrVersionNotFound
+	}
+	if version, rawVersion, isValid := sourceForge.filter.parseFilePath(bestRelease.Release.Filename); isValid {
+		sourceForge.latestRawVersion = rawVersion
+		return version, nil
+	}
+	return "", ErrVersionNotFound
+}
+
+func (sourceForge *sourceForgeProvider) rssVersion() (Version, error) {
+	var rss sourceForgeRSSResp
+	if err := sourceForge.httpClient.getXML(sourceForge.rssURL(), &rss, nil); err != nil {
+		return "", err
+	}
+
+	// Files are listed from the newest
+	for _, item := range rss.Items {
+		if version, rawVersion, isValid := sourceForge.filter.parseFilePath(item.Title); isValid {
+			sourceForge.latestRawVersion = rawVersion
+			return version, nil
+		}
+	}
+
+	return "", ErrVersionNotFound
+}
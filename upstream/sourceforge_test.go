@@ -0,0 +1,130 @@
+package upstream
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+)
+
+const sourceForgeRSS = `<?xml version="1.0" encoding="utf-8"?>
+<rss xmlns:content="http://purl.org/rss/1.0/modules/content/" version="2.0">
+  <channel>
+    <title>Foo</title>
+    <item><title><![CDATA[/foo-gui/2.0.0/foo-gui-2.0.0.tar.gz]]></title></item>
+    <item><title><![CDATA[/foo/1.5.1/foo-1.5.1.tar.gz]]></title></item>
+    <item><title><![CDATA[/foo/1.5.0/foo-1.5.0.tar.gz]]></title></item>
+  </channel>
+</rss>`
+
+func TestNewSourceForge_Valid(t *testing.T) {
+	cases := map[string]sourceForgeProvider{
+		"https://downloads.sourceforge.net/project/foo/foo/1.2/foo-1.2.tar.gz": {project: "foo"},
+		"https://downloads.sourceforge.net/foo-bar/foo-bar-1.2.tar.gz":         {project: "foo-bar"},
+		"https://downloads.sourceforge.net/sourceforge/foo/foo-1.2.tar.gz":     {project: "foo"},
+		"https://sourceforge.net/projects/foo/files/foo-1.2.tar.gz/download":   {project: "foo"},
+		"https://sourceforge.net/projects/foo.bar":                             {project: "foo.bar"},
+	}
+
+	emptyConfig := testConfig(t, "{}")
+	for validURL, expectedResult := range cases {
+		expectedResult.baseURL = defaultSourceForgeBaseURL
+		expectedResult.httpClient = testHTTPClient
+		result := newSourceForgeProvider(validURL, emptyConfig, versionFilter{}, testHTTPClient)
+		assert.Equal(t, &expectedResult, result, validURL)
+	}
+}
+
+func TestNewSourceForge_Invalid(t *testing.T) {
+	invalidURL := "https://whatever.url/project/foo/foo/1.2/foo-1.2.tar.gz"
+
+	result := newSourceForgeProvider(invalidURL, testConfig(t, "{}"), versionFilter{}, testHTTPClient)
+	assert.Nil(t, result)
+}
+
+func TestSourceForgeLatestVersion_BestRelease(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://sourceforge.net").
+		Get("/projects/foo/best_release.json").
+		Reply(200).
+		JSON(map[string]interface{}{
+			"release": map[string]string{"filename": "/foo/1.5.1/foo-1.5.1.tar.gz"},
+		})
+
+	sourceForge := sourceForgeProvider{project: "foo", baseURL: defaultSourceForgeBaseURL, httpClient: testHTTPClient}
+
+	result, err := sourceForge.LatestVersion()
+
+	assert.NoError(t, err)
+	assert.Equal(t, Version("1.5.1"), result)
+}
+
+func TestSourceForgeLatestVersion_RSS(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://sourceforge.net").
+		Get("/projects/foo/best_release.json").
+		Reply(200).
+		JSON(map[string]interface{}{
+			"release": map[string]string{"filename": "/foo-gui/2.0.0/foo-gui-2.0.0.tar.gz"},
+		})
+	gock.New("https://sourceforge.net").
+		Get("/projects/foo/rss").
+		MatchParam("path", "/").
+		Reply(200).
+		BodyString(sourceForgeRSS)
+
+	filter := versionFilter{filePattern: regexp.MustCompile(`^/foo/[^/]+/foo-(?P<version>[\d.]+)\.tar\.gz$`)}
+	sourceForge := sourceForgeProvider{project: "foo", baseURL: defaultSourceForgeBaseURL, filter: filter, httpClient: testHTTPClient}
+
+	result, err := sourceForge.LatestVersion()
+
+	assert.NoError(t, err)
+	assert.Equal(t, Version("1.5.1"), result)
+	assert.True(t, gock.IsDone())
+}
+
+func TestSourceForgeLatestVersion_NoBestRelease(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://sourceforge.net").
+		Get("/projects/foo/best_release.json").
+		Reply(200).
+		JSON(map[string]interface{}{"release": nil})
+	gock.New("https://sourceforge.net").
+		Get("/projects/foo/rss").
+		Reply(200).
+		BodyString(sourceForgeRSS)
+
+	sourceForge := sourceForgeProvider{project: "foo", baseURL: defaultSourceForgeBaseURL, httpClient: testHTTPClient}
+
+	result, err := sourceForge.LatestVersion()
+
+	assert.NoError(t, err)
+	assert.Equal(t, Version("2.0.0"), result)
+}
+
+func TestSourceForgeLatestVersion_NotFound(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://sourceforge.net").
+		Get("/projects/foo/best_release.json").
+		Reply(404).
+		BodyString("Not found")
+	gock.New("https://sourceforge.net").
+		Get("/projects/foo/rss").
+		Reply(404).
+		BodyString("Not found")
+
+	sourceForge := sourceForgeProvider{project: "foo", baseURL: defaultSourceForgeBaseURL, httpClient: testHTTPClient}
+
+	_, err := sourceForge.LatestVersion()
+
+	assert.ErrorIs(t, err, ErrVersionNotFound)
+}
+
+func TestSourceForgeEqual(t *testing.T) {
+	sourceForge := &sourceForgeProvider{project: "foo", baseURL: defaultSourceForgeBaseURL}
+
+	assert.True(t, sourceForge.Equal(&sourceForgeProvider{project: "foo", baseURL: defaultSourceForgeBaseURL}))
+	assert.False(t, sourceForge.Equal(&sourceForgeProvider{project: "bar", baseURL: defaultSourceForgeBaseURL}))
+	assert.False(t, sourceForge.Equal(&pypiProvider{packageName: "foo"}))
+}
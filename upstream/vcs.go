@@ -0,0 +1,137 @@
+package upstream
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.uber.org/config"
+)
+
+// VCSBehindUnknown is used as VCSStatus.Behind when it's known that the package is behind,
+// but not by how many commits.
+const VCSBehindUnknown = -1
+
+// Matches r<count>.<hash> and <tag>.r<count>.g<hash> pkgvers
+var vcsPkgverRegex = regexp.MustCompile(`^(?:(.+)\.)?r(\d+)\.g?([0-9a-f]{7,40})$`)
+
+// VCSRevision identifies a commit of a VCS package, as encoded in its pkgver.
+type VCSRevision struct {
+	// Tag is the latest tag before the commit, empty if the pkgver doesn't contain it
+	Tag string
+	// Count is the number of commits since the tag, or since the beginning if there's no tag
+	Count int
+	Hash  string
+}
+
+// ParseVCSPkgver parses VCS pkgver in one of the formats recommended by the VCS package guidelines:
+// r<count>.<hash> or <tag>.r<count>.g<hash>.
+func ParseVCSPkgver(pkgver string) (VCSRevision, bool) {
+	match := vcsPkgverRegex.FindStringSubmatch(pkgver)
+	if match == nil {
+		return VCSRevision{}, false
+	}
+	count, _ := strconv.Atoi(match[2])
+	return VCSRevision{Tag: match[1], Count: count, Hash: match[3]}, true
+}
+
+// VCSStatus describes the upstream repo state relative to the packaged revision.
+type VCSStatus struct {
+	// Head is the hash of the upstream commit the package should be at
+	Head string
+	// Behind is the number of commits the package is behind the head, or VCSBehindUnknown
+	Behind int
+}
+
+// VCSChecker compares revisions of VCS packages with their upstream git repos.
+type VCSChecker struct {
+	httpClient      *HTTPClient
+	commandRunner   CommandRunner
+	providersConfig config.Value
+}
+
+func NewVCSChecker(httpClient *HTTPClient, commandRunner CommandRunner, providersConfig config.Value) *VCSChecker {
+	return &VCSChecker{httpClient: httpClient, commandRunner: commandRunner, providersConfig: providersConfig}
+}
+
+// Check finds the upstream head using 'git ls-remote' and, if the revision is not at the head,
+// tries to count the commits between them using GitHub or GitLab API.
+// Source is a VCS source entry, e.g. git+https://github.com/foo/bar.git#branch=dev.
+func (checker *VCSChecker) Check(source string, revision VCSRevision) (VCSStatus, error) {
+	repoURL, ref, err := parseGitSource(source)
+	if err != nil {
+		return VCSStatus{}, err
+	}
+
+	head, err := checker.lsRemote(repoURL, ref)
+	if err != nil {
+		return VCSStatus{}, err
+	}
+	if strings.HasPrefix(head, revision.Hash) {
+		return VCSStatus{Head: head, Behind: 0}, nil
+	}
+
+	// Commit count is just informative, so forge API errors are not reported
+	behind := VCSBehindUnknown
+	forgeURL := strings.TrimSuffix(repoURL, ".git")
+	if gitHub := newGitHubProvider(forgeURL, checker.providersConfig.Get("github"), versionFilter{}, checker.httpClient); gitHub != nil {
+		if commitsBehind, err := gitHub.commitsBehind(revision.Hash, head); err == nil {
+			behind = commitsBehind
+		}
+	} else if gitLab := newGitLabProvider(forgeURL, checker.providersConfig.Get("gitlab"), versionFilter{}, checker.httpClient); gitLab != nil {
+		if commitsBehind, err := gitLab.commitsBehind(revision.Hash, head); err == nil {
+			behind = commitsBehind
+		}
+	}
+
+	return VCSStatus{Head: head, Behind: behind}, nil
+}
+
+// lsRemote returns hash of the commit the ref points at in the remote repo.
+func (checker *VCSChecker) lsRemote(repoURL string, ref string) (string, error) {
+	output, err := checker.commandRunner("", "git", "ls-remote", repoURL, ref, ref+"^{}")
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrProviderError, err)
+	}
+
+	head := ""
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		hash, lineRef, found := strings.Cut(line, "\t")
+		if !found {
+			continue
+		}
+		// Peeled annotated tag points at the commit, the tag itself doesn't
+		if head == "" || strings.HasSuffix(lineRef, "^{}") {
+			head = hash
+		}
+	}
+
+	if head == "" {
+		return "", fmt.Errorf("%w: ref %s not found in %s", ErrVersionNotFound, ref, repoURL)
+	}
+	return head, nil
+}
+
+// parseGitSource returns repo URL and ref from git source entry, e.g. git+https://foo.bar/baz.git#branch=dev.
+func parseGitSource(source string) (string, string, error) {
+	repoURL, fragment, _ := strings.Cut(source, "#")
+	repoURL, _, _ = strings.Cut(repoURL, "?")
+	if withoutPrefix, isGitPlus := strings.CutPrefix(repoURL, "git+"); isGitPlus {
+		repoURL = withoutPrefix
+	} else if !strings.HasPrefix(repoURL, "git://") {
+		return "", "", fmt.Errorf("%w: unsupported VCS source %s", ErrProviderError, source)
+	}
+
+	fragmentType, fragmentValue, _ := strings.Cut(fragment, "=")
+	switch fragmentType {
+	case "":
+		return repoURL, "HEAD", nil
+	case "branch":
+		return repoURL, "refs/heads/" + fragmentValue, nil
+	case "tag":
+		return repoURL, "refs/tags/" + fragmentValue, nil
+	default:
+		return "", "", fmt.Errorf("%w: source %s is pinned to %s", ErrProviderError, source, fragmentType)
+	}
+}
@@ -0,0 +1,148 @@
+package upstream
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bcyran/bumper/internal/testutils"
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testHeadHash  = "89abcdef0123456789abcdef0123456789abcdef"
+	testStaleHash = "0123456"
+)
+
+func TestParseVCSPkgver(t *testing.T) {
+	cases := map[string]VCSRevision{
+		"r123.0123abc":            {Tag: "", Count: 123, Hash: "0123abc"},
+		"1.2.3.r5.g0123abc":       {Tag: "1.2.3", Count: 5, Hash: "0123abc"},
+		"v1.2.r10.0123456789abcd": {Tag: "v1.2", Count: 10, Hash: "0123456789abcd"},
+	}
+
+	for pkgver, expectedRevision := range cases {
+		revision, isValid := ParseVCSPkgver(pkgver)
+		assert.True(t, isValid, pkgver)
+		assert.Equal(t, expectedRevision, revision, pkgver)
+	}
+}
+
+func TestParseVCSPkgver_Invalid(t *testing.T) {
+	for _, pkgver := range []string{"1.2.3", "r123", "1.2.r5.gxyz1234", "20230101"} {
+		_, isValid := ParseVCSPkgver(pkgver)
+		assert.False(t, isValid, pkgver)
+	}
+}
+
+func TestParseGitSource(t *testing.T) {
+	cases := map[string][2]string{
+		"git+https://github.com/foo/bar.git":               {"https://github.com/foo/bar.git", "HEAD"},
+		"git+https://github.com/foo/bar.git#branch=dev":    {"https://github.com/foo/bar.git", "refs/heads/dev"},
+		"git+https://github.com/foo/bar.git?signed#tag=v1": {"https://github.com/foo/bar.git", "refs/tags/v1"},
+		"git://git.example.com/bar.git":                    {"git://git.example.com/bar.git", "HEAD"},
+	}
+
+	for source, expected := range cases {
+		repoURL, ref, err := parseGitSource(source)
+		assert.NoError(t, err, source)
+		assert.Equal(t, expected, [2]string{repoURL, ref}, source)
+	}
+}
+
+func TestParseGitSource_Invalid(t *testing.T) {
+	for _, source := range []string{"https://foo.bar/baz.tar.gz", "hg+https://foo.bar/baz", "git+https://foo.bar/baz.git#commit=0123456"} {
+		_, _, err := parseGitSource(source)
+		assert.ErrorIs(t, err, ErrProviderError, source)
+	}
+}
+
+func TestVCSCheck_UpToDate(t *testing.T) {
+	commandRetvals := []testutils.CommandRunnerRetval{
+		{Stdout: []byte("0123456789abcdef0123456789abcdef01234567\tHEAD\n"), Err: nil},
+	}
+	fakeRunner, commandRuns := testutils.MakeFakeCommandRunner(&commandRetvals)
+	checker := NewVCSChecker(testHTTPClient, fakeRunner, testConfig(t, "{}"))
+
+	status, err := checker.Check("git+https://github.com/foo/bar.git", VCSRevision{Count: 10, Hash: testStaleHash})
+
+	assert.NoError(t, err)
+	assert.Equal(t, VCSStatus{Head: "0123456789abcdef0123456789abcdef01234567", Behind: 0}, status)
+	expectedRun := testutils.CommandRunnerParams{Cwd: "", Command: "git", Args: []string{"ls-remote", "https://github.com/foo/bar.git", "HEAD", "HEAD^{}"}}
+	assert.Equal(t, []testutils.CommandRunnerParams{expectedRun}, *commandRuns)
+}
+
+func TestVCSCheck_GitHub(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.github.com").
+		Get("/repos/foo/bar/compare/" + testStaleHash + "..." + testHeadHash).
+		Reply(200).
+		JSON(map[string]int{"ahead_by": 7})
+	commandRetvals := []testutils.CommandRunnerRetval{
+		{Stdout: []byte("fedcba9876543210fedcba9876543210fedcba98\trefs/tags/v1\n" + testHeadHash + "\trefs/tags/v1^{}\n"), Err: nil},
+	}
+	fakeRunner, _ := testutils.MakeFakeCommandRunner(&commandRetvals)
+	checker := NewVCSChecker(testHTTPClient, fakeRunner, testConfig(t, "{}"))
+
+	status, err := checker.Check("git+https://github.com/foo/bar.git#tag=v1", VCSRevision{Count: 10, Hash: testStaleHash})
+
+	assert.NoError(t, err)
+	assert.Equal(t, VCSStatus{Head: testHeadHash, Behind: 7}, status)
+	assert.True(t, gock.IsDone())
+}
+
+func TestVCSCheck_GitLab(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://gitlab.com").
+		Get("/api/v4/projects/foo/bar/repository/compare").
+		MatchParam("from", testStaleHash).
+		MatchParam("to", testHeadHash).
+		Reply(200).
+		JSON(map[string]any{"commits": []map[string]string{{"id": "a"}, {"id": "b"}}})
+	commandRetvals := []testutils.CommandRunnerRetval{
+		{Stdout: []byte(testHeadHash + "\trefs/heads/dev\n"), Err: nil},
+	}
+	fakeRunner, _ := testutils.MakeFakeCommandRunner(&commandRetvals)
+	checker := NewVCSChecker(testHTTPClient, fakeRunner, testConfig(t, "{}"))
+
+	status, err := checker.Check("git+https://gitlab.com/foo/bar#branch=dev", VCSRevision{Count: 10, Hash: testStaleHash})
+
+	assert.NoError(t, err)
+	assert.Equal(t, VCSStatus{Head: testHeadHash, Behind: 2}, status)
+	assert.True(t, gock.IsDone())
+}
+
+func TestVCSCheck_UnknownCount(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.github.com").
+		Get("/repos/foo/bar/compare/" + testStaleHash + "..." + testHeadHash).
+		Reply(404)
+	commandRetvals := []testutils.CommandRunnerRetval{
+		{Stdout: []byte(testHeadHash + "\tHEAD\n"), Err: nil},
+		{Stdout: []byte(testHeadHash + "\tHEAD\n"), Err: nil},
+	}
+	fakeRunner, _ := testutils.MakeFakeCommandRunner(&commandRetvals)
+	checker := NewVCSChecker(testHTTPClient, fakeRunner, testConfig(t, "{}"))
+
+	for _, source := range []string{"git+https://github.com/foo/bar.git", "git+https://example.com/foo/bar.git"} {
+		status, err := checker.Check(source, VCSRevision{Count: 10, Hash: testStaleHash})
+
+		assert.NoError(t, err, source)
+		assert.Equal(t, VCSStatus{Head: testHeadHash, Behind: VCSBehindUnknown}, status, source)
+	}
+}
+
+func TestVCSCheck_Failed(t *testing.T) {
+	commandRetvals := []testutils.CommandRunnerRetval{
+		{Stdout: []byte{}, Err: errors.New("exit status 128")},
+		{Stdout: []byte{}, Err: nil},
+	}
+	fakeRunner, _ := testutils.MakeFakeCommandRunner(&commandRetvals)
+	checker := NewVCSChecker(testHTTPClient, fakeRunner, testConfig(t, "{}"))
+
+	_, err := checker.Check("git+https://example.com/foo/bar.git", VCSRevision{Count: 10, Hash: testStaleHash})
+	assert.ErrorIs(t, err, ErrProviderError)
+
+	_, err = checker.Check("git+https://example.com/foo/bar.git", VCSRevision{Count: 10, Hash: testStaleHash})
+	assert.ErrorIs(t, err, ErrVersionNotFound)
+}
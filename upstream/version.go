@@ -37,3 +37,23 @@ func containsDigit(str string) bool {
 	}
 	return false
 }
+
+// rawVersionRecorder implements RawVersionProvider for the providers embedding it.
+type rawVersionRecorder struct {
+	// latestRawVersion is the upstream version string found by LatestVersion, before it was normalised
+	latestRawVersion string
+}
+
+func (recorder *rawVersionRecorder) LatestRawVersion() string {
+	return recorder.latestRawVersion
+}
+
+// parseRawVersion makes Version from the upstream version string, like ParseVersion,
+// and records the string if it's valid.
+func (recorder *rawVersionRecorder) parseRawVersion(rawVersion string) (Version, bool) {
+	version, isValid := ParseVersion(rawVersion)
+	if isValid {
+		recorder.latestRawVersion = rawVersion
+	}
+	return version, isValid
+}
@@ -0,0 +1,311 @@
+package upstream
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/bcyran/bumper/internal/vercmp"
+	"go.uber.org/config"
+)
+
+// watchPackagePlaceholder is replaced with pkgbase, both in the URL and in the pattern
+const watchPackagePlaceholder = "@PACKAGE@"
+
+// Regexes of the other placeholders supported in the watch line patterns, as defined by uscan
+var watchPlaceholders = map[string]string{
+	"@ANY_VERSION@":   `[-_]?v?(\d[\-+\.:\~\da-zA-Z]*)`,
+	"@ARCHIVE_EXT@":   `(?:\.(?:tar\.xz|tar\.bz2|tar\.gz|tar\.zst|tar\.lzma|zip|tgz|tbz|txz))`,
+	"@SIGNATURE_EXT@": `(?:\.(?:tar\.xz|tar\.bz2|tar\.gz|tar\.zst|tar\.lzma|zip|tgz|tbz|txz))(?:\.(?:asc|pgp|gpg|sig|sign))`,
+}
+
+var (
+	// Matches uscan placeholders, e.g. @PACKAGE@
+	watchPlaceholderRegex = regexp.MustCompile(`@[A-Z][A-Z_]*@`)
+	watchHrefRegex        = regexp.MustCompile(`(?i)href\s*=\s*["']([^"']+)["']`)
+	// Matches Perl group references in the replacement, e.g. $1 or \1
+	perlGroupRefRegex = regexp.MustCompile(`^[$\\](\d+)`)
+)
+
+// watchLine is a uscan-style watch line: [opts=...] URL [pattern].
+// If the pattern is omitted, it's the last path segment of the URL.
+type watchLine struct {
+	url     string
+	pattern *regexp.Regexp
+	// uversionmangle holds substitutions applied to the matched version
+	uversionmangle []watchSubstitution
+}
+
+// watchSubstitution is a Perl-like s/regex/replacement/flags substitution.
+type watchSubstitution struct {
+	regex       *regexp.Regexp
+	replacement string
+	global      bool
+}
+
+// newWatchLine parses the watch line configured for the package, returns nil if there's none.
+func newWatchLine(pkgbase string, packageConfig config.Value) (*watchLine, error) {
+	var rawLine string
+	packageConfig.Get("watch").Populate(&rawLine) //nolint:errcheck
+	if rawLine == "" {
+		return nil, nil
+	}
+	watch, err := parseWatchLine(rawLine, pkgbase)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid watch line: %w", ErrInvalidPackageConfig, err)
+	}
+	return watch, nil
+}
+
+// parseWatchLine parses the watch line of the package with the given pkgbase, which replaces @PACKAGE@ placeholder.
+func parseWatchLine(rawLine string, pkgbase string) (*watchLine, error) {
+	line := strings.TrimSpace(rawLine)
+	watch := watchLine{}
+
+	if rawOpts, isOpts := strings.CutPrefix(line, "opts="); isOpts {
+		var opts string
+		if quotedOpts, isQuoted := strings.CutPrefix(rawOpts, `"`); isQuoted {
+			var found bool
+			if opts, line, found = strings.Cut(quotedOpts, `"`); !found {
+				return nil, fmt.Errorf("unclosed opts quote")
+			}
+		} else {
+			opts, line, _ = strings.Cut(rawOpts, " ")
+		}
+		if err := watch.parseOpts(opts); err != nil {
+			return nil, err
+		}
+	}
+
+	// Version and script fields are ignored
+	fields := strings.Fields(line)
+	var rawPattern string
+	switch len(fields) {
+	case 0:
+		return nil, fmt.Errorf("missing URL")
+	case 1:
+		lastSlash := strings.LastIndex(fields[0], "/")
+		watch.url, rawPattern = fields[0][:lastSlash+1], fields[0][lastSlash+1:]
+	default:
+		watch.url, rawPattern = fields[0], fields[1]
+	}
+
+	var err error
+	if watch.url, err = expandWatchPlaceholders(watch.url, pkgbase, nil); err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	if rawPattern, err = expandWatchPlaceholders(rawPattern, regexp.QuoteMeta(pkgbase), watchPlaceholders); err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+	if watch.pattern, err = regexp.Compile(`(?:^|/)` + rawPattern + `$`); err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+	if watch.pattern.NumSubexp() == 0 {
+		return nil, fmt.Errorf("pattern has no version group")
+	}
+
+	return &watch, nil
+}
+
+// expandWatchPlaceholders replaces @PACKAGE@ with the given package and other placeholders with their values.
+// Returns error if there's a placeholder which is not supported.
+func expandWatchPlaceholders(str string, pkg string, placeholders map[string]string) (string, error) {
+	var err error
+	expanded := watchPlaceholderRegex.ReplaceAllStringFunc(str, func(placeholder string) string {
+		if placeholder == watchPackagePlaceholder {
+			return pkg
+		}
+		if value, isSupported := placeholders[placeholder]; isSupported {
+			return value
+		}
+		if err == nil {
+			err = fmt.Errorf("unsupported placeholder '%s'", placeholder)
+		}
+		return placeholder
+	})
+	return expanded, err
+}
+
+// parseOpts parses comma separated watch line options, all of them except uversionmangle are ignored.
+func (watch *watchLine) parseOpts(opts string) error {
+	for _, opt := range strings.Split(opts, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(opt), "=")
+		if name != "uversionmangle" {
+			continue
+		}
+		for _, rawSubstitution := range strings.Split(value, ";") {
+			substitution, err := parseWatchSubstitution(strings.TrimSpace(rawSubstitution))
+			if err != nil {
+				return err
+			}
+			watch.uversionmangle = append(watch.uversionmangle, substitution)
+		}
+	}
+	return nil
+}
+
+func parseWatchSubstitution(rawSubstitution string) (watchSubstitution, error) {
+	body, isSubstitution := strings.CutPrefix(rawSubstitution, "s")
+	if !isSubstitution || len(body) < 3 {
+		return watchSubstitution{}, fmt.Errorf("unsupported mangle rule '%s'", rawSubstitution)
+	}
+
+	parts := splitWatchSubstitution(body)
+	if len(parts) != 3 {
+		return watchSubstitution{}, fmt.Errorf("invalid mangle rule '%s'", rawSubstitution)
+	}
+
+	regex, err := regexp.Compile(parts[0])
+	if err != nil {
+		return watchSubstitution{}, fmt.Errorf("invalid mangle rule '%s': %w", rawSubstitution, err)
+	}
+
+	return watchSubstitution{
+		regex:       regex,
+		replacement: watchReplacement(parts[1]),
+		global:      strings.Contains(parts[2], "g"),
+	}, nil
+}
+
+// splitWatchSubstitution splits substitution body, e.g. '/a/b/g', on the delimiters not preceded by a backslash.
+// Escaped delimiters lose the backslash, other escape sequences are kept for the regex and the replacement.
+func splitWatchSubstitution(body string) []string {
+	delimiter := body[0]
+	parts := []string{}
+	part := strings.Builder{}
+	for i := 1; i < len(body); i++ {
+		switch {
+		case body[i] == '\\' && i+1 < len(body) && body[i+1] == delimiter:
+			i++
+			// delimiter is a literal character, even if it has a special meaning in the regex
+			if len(parts) == 0 {
+				part.WriteString(regexp.QuoteMeta(string(delimiter)))
+			} else {
+				part.WriteByte(delimiter)
+			}
+		case body[i] == '\\' && i+1 < len(body):
+			part.WriteString(body[i : i+2])
+			i++
+		case body[i] == delimiter:
+			parts = append(parts, part.String())
+			part.Reset()
+		default:
+			part.WriteByte(body[i])
+		}
+	}
+	return append(parts, part.String())
+}
+
+// watchReplacement converts Perl substitution replacement to regexp.Expand template.
+// Group references, \N and $N, become ${N}, other escaped characters lose the backslash, e.g. \. becomes '.',
+// and dollar signs not starting a group reference are literal.
+func watchReplacement(rawReplacement string) string {
+	replacement := strings.Builder{}
+	for i := 0; i < len(rawReplacement); i++ {
+		if groupRef := perlGroupRefRegex.FindStringSubmatch(rawReplacement[i:]); groupRef != nil {
+			replacement.WriteString("${" + groupRef[1] + "}")
+			i += len(groupRef[0]) - 1
+			continue
+		}
+		char := rawReplacement[i]
+		switch {
+		case char == '\\' && i+1 < len(rawReplacement):
+			i++
+			char = rawReplacement[i]
+		case char == '$' && strings.HasPrefix(rawReplacement[i:], "${"):
+			replacement.WriteByte(char)
+			continue
+		}
+		if char == '$' {
+			replacement.WriteString("$$")
+		} else {
+			replacement.WriteByte(char)
+		}
+	}
+	return replacement.String()
+}
+
+func (substitution watchSubstitution) apply(str string) string {
+	if substitution.global {
+		return substitution.regex.ReplaceAllString(str, substitution.replacement)
+	}
+	match := substitution.regex.FindStringSubmatchIndex(str)
+	if match == nil {
+		return str
+	}
+	replaced := substitution.regex.ExpandString(nil, substitution.replacement, str, match)
+	return str[:match[0]] + string(replaced) + str[match[1]:]
+}
+
+// matchVersion returns version from the link matching the pattern, with the mangle rules applied,
+// along with the upstream version string before mangling. Multiple pattern groups are joined with dots.
+func (watch *watchLine) matchVersion(link string) (Version, string, bool) {
+	match := watch.pattern.FindStringSubmatch(link)
+	if match == nil {
+		return "", "", false
+	}
+
+	groups := []string{}
+	for _, group := range match[1:] {
+		if group != "" {
+			groups = append(groups, group)
+		}
+	}
+	rawVersion := strings.Join(groups, ".")
+	mangledVersion := rawVersion
+	for _, substitution := range watch.uversionmangle {
+		mangledVersion = substitution.apply(mangledVersion)
+	}
+
+	version, isValid := ParseVersion(mangledVersion)
+	return version, rawVersion, isValid
+}
+
+// watchProvider finds the latest version in the links of a web page, according to the watch line.
+type watchProvider struct {
+	rawVersionRecorder
+	watch      *watchLine
+	httpClient *HTTPClient
+}
+
+// newWatchProvider creates watchProvider if the watch line is configured for the package, URL is irrelevant.
+func newWatchProvider(watch *watchLine, httpClient *HTTPClient) *watchProvider {
+	if watch == nil {
+		return nil
+	}
+	return &watchProvider{watch: watch, httpClient: httpClient}
+}
+
+func (watchProv *watchProvider) Equal(other interface{}) bool {
+	switch other := other.(type) {
+	case *watchProvider:
+		return watchProv.watch.url == other.watch.url && watchProv.watch.pattern.String() == other.watch.pattern.String()
+	default:
+		return false
+	}
+}
+
+// LatestVersion returns the highest version matched in the page links.
+func (watchProv *watchProvider) LatestVersion() (Version, error) {
+	page, err := watchProv.httpClient.getText(watchProv.watch.url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	var highestVersion Version
+	var highestRawVersion string
+	for _, hrefMatch := range watchHrefRegex.FindAllStringSubmatch(page, -1) {
+		version, rawVersion, isValid := watchProv.watch.matchVersion(html.UnescapeString(hrefMatch[1]))
+		if isValid && (highestVersion == "" || vercmp.Rpmvercmp(string(version), string(highestVersion)) > 0) {
+			highestVersion = version
+			highestRawVersion = rawVersion
+		}
+	}
+
+	if highestVersion == "" {
+		return "", ErrVersionNotFound
+	}
+	watchProv.latestRawVersion = highestRawVersion
+	return highestVersion, nil
+}
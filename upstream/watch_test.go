@@ -0,0 +1,188 @@
+package upstream
+
+import (
+	"testing"
+
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const watchPage = `<html><body>
+<a href="foo-1.9.0.tar.gz">foo-1.9.0.tar.gz</a>
+<a href="/downloads/foo-1.10.0.tar.gz">foo-1.10.0.tar.gz</a>
+<a href='https://example.com/downloads/foo-1.11.0-rc1.tar.gz'>foo-1.11.0-rc1.tar.gz</a>
+<a HREF="foo-1.10.0.tar.gz.asc">signature</a>
+<a href="foo-docs-2.0.0.tar.gz">docs</a>
+</body></html>`
+
+func TestParseWatchLine(t *testing.T) {
+	cases := map[string]string{
+		`https://example.com/downloads/ foo-([\d.]+)\.tar\.gz`:                                        "https://example.com/downloads/",
+		`https://example.com/downloads/foo-([\d.]+)\.tar\.gz`:                                         "https://example.com/downloads/",
+		`opts=uversionmangle=s/-rc/rc/ https://example.com/ foo-(.+).tgz`:                             "https://example.com/",
+		`opts="pgpmode=none, uversionmangle=s/a/b/" https://example.com/ foo-(.+).tgz debian uupdate`: "https://example.com/",
+		`  https://example.com/ foo@ANY_VERSION@@ARCHIVE_EXT@  `:                                      "https://example.com/",
+		`https://example.com/@PACKAGE@/ .*/@PACKAGE@@ANY_VERSION@@ARCHIVE_EXT@`:                       "https://example.com/foo/",
+	}
+
+	for rawLine, expectedURL := range cases {
+		watch, err := parseWatchLine(rawLine, "foo")
+		require.NoError(t, err, rawLine)
+		assert.Equal(t, expectedURL, watch.url, rawLine)
+	}
+}
+
+func TestParseWatchLine_Invalid(t *testing.T) {
+	cases := []string{
+		`   `,
+		`https://example.com/ foo-([\d.]+`,
+		`https://example.com/ foo-1.0.tar.gz`,
+		`opts="uversionmangle=s/a/b/ https://example.com/ foo-(.+).tgz`,
+		`opts=uversionmangle=tr/a-z/A-Z/ https://example.com/ foo-(.+).tgz`,
+		`opts=uversionmangle=s/a/b https://example.com/ foo-(.+).tgz`,
+		`opts=uversionmangle=s/(/b/ https://example.com/ foo-(.+).tgz`,
+		`https://example.com/ @PACKAGE@@ANY_VERSION@@DEB_EXT@`,
+		`https://example.com/@COMPONENT@/ foo-(.+).tgz`,
+	}
+
+	for _, rawLine := range cases {
+		_, err := parseWatchLine(rawLine, "foo")
+		assert.Error(t, err, rawLine)
+	}
+}
+
+func TestParseWatchLine_UnsupportedPlaceholder(t *testing.T) {
+	_, err := parseWatchLine(`https://example.com/ @PACKAGE@@ANY_VERSION@@DEB_EXT@`, "foo")
+
+	assert.ErrorContains(t, err, "unsupported placeholder '@DEB_EXT@'")
+}
+
+func TestNewWatchLine(t *testing.T) {
+	watch, err := newWatchLine("foo", testConfig(t, "{test: {}}"))
+	assert.NoError(t, err)
+	assert.Nil(t, watch)
+
+	_, err = newWatchLine("foo", testConfig(t, "{test: {watch: 'https://example.com/ foo'}}"))
+	assert.ErrorIs(t, err, ErrInvalidPackageConfig)
+}
+
+func TestWatchLineMatchVersion(t *testing.T) {
+	cases := []struct {
+		rawLine         string
+		link            string
+		expectedVersion Version
+		expectedValid   bool
+	}{
+		{rawLine: `https://e.com/ foo-([\d.]+)\.tar\.gz`, link: "foo-1.2.3.tar.gz", expectedVersion: "1.2.3", expectedValid: true},
+		{rawLine: `https://e.com/ foo-([\d.]+)\.tar\.gz`, link: "https://e.com/dl/foo-1.2.3.tar.gz", expectedVersion: "1.2.3", expectedValid: true},
+		{rawLine: `https://e.com/ foo-([\d.]+)\.tar\.gz`, link: "barfoo-1.2.3.tar.gz", expectedVersion: "", expectedValid: false},
+		{rawLine: `https://e.com/ foo-([\d.]+)\.tar\.gz`, link: "foo-1.2.3.tar.gz.asc", expectedVersion: "", expectedValid: false},
+		{rawLine: `https://e.com/ foo-(\d+)_(\d+)\.zip`, link: "foo-1_2.zip", expectedVersion: "1.2", expectedValid: true},
+		{rawLine: `https://e.com/ foo@ANY_VERSION@@ARCHIVE_EXT@`, link: "foo-v2.0.1.tar.xz", expectedVersion: "2.0.1", expectedValid: true},
+		{rawLine: `https://e.com/ .*/@PACKAGE@@ANY_VERSION@@ARCHIVE_EXT@`, link: "https://e.com/dl/foo-2.0.1.tar.gz", expectedVersion: "2.0.1", expectedValid: true},
+		{rawLine: `https://e.com/ .*/@PACKAGE@@ANY_VERSION@@ARCHIVE_EXT@`, link: "https://e.com/dl/foobar-2.0.1.tar.gz", expectedVersion: "", expectedValid: false},
+		{rawLine: `opts=uversionmangle=s/-rc/rc/ https://e.com/ foo-(.+)\.tgz`, link: "foo-1.0-rc1.tgz", expectedVersion: "1.0rc1", expectedValid: true},
+		{rawLine: `opts=uversionmangle=s/_/./g;s/^v// https://e.com/ foo-(.+)\.tgz`, link: "foo-v1_2_3.tgz", expectedVersion: "1.2.3", expectedValid: true},
+		{rawLine: `opts=uversionmangle=s/_/./ https://e.com/ foo-(.+)\.tgz`, link: "foo-1_2_3.tgz", expectedVersion: "1.2_3", expectedValid: true},
+		{rawLine: `opts=uversionmangle=s/(\d+)-(\d+)/$1.\2/ https://e.com/ foo-(.+)\.tgz`, link: "foo-1-2.tgz", expectedVersion: "1.2", expectedValid: true},
+		// escaped delimiters
+		{rawLine: `opts=uversionmangle=s/^release\/// https://e.com/ (.+)\.tgz`, link: "release/1.2.tgz", expectedVersion: "1.2", expectedValid: true},
+		{rawLine: `opts=uversionmangle=s|1\|2|3| https://e.com/ foo-(.+)\.tgz`, link: "foo-1|2.0.tgz", expectedVersion: "3.0", expectedValid: true},
+		// escapes in the replacement
+		{rawLine: `opts=uversionmangle=s/_/\./g https://e.com/ foo-(.+)\.tgz`, link: "foo-1_2_3.tgz", expectedVersion: "1.2.3", expectedValid: true},
+		{rawLine: `opts=uversionmangle=s/(\d+)(\d)$/\1\.\2/ https://e.com/ foo-(.+)\.tgz`, link: "foo-123.tgz", expectedVersion: "12.3", expectedValid: true},
+	}
+
+	for _, testCase := range cases {
+		watch, err := parseWatchLine(testCase.rawLine, "foo")
+		require.NoError(t, err, testCase.rawLine)
+
+		version, _, isValid := watch.matchVersion(testCase.link)
+
+		assert.Equal(t, testCase.expectedValid, isValid, testCase.rawLine)
+		assert.Equal(t, testCase.expectedVersion, version, testCase.rawLine)
+	}
+}
+
+func TestParseWatchSubstitution(t *testing.T) {
+	cases := map[string]string{
+		`s/-/\//`:      "1/2",
+		`s/-/\\/`:      `1\2`,
+		`s/-(\d)/\$1/`: "1$1",
+		`s#-#\##`:      "1#2",
+	}
+
+	for rawSubstitution, expected := range cases {
+		substitution, err := parseWatchSubstitution(rawSubstitution)
+		require.NoError(t, err, rawSubstitution)
+
+		assert.Equal(t, expected, substitution.apply("1-2"), rawSubstitution)
+	}
+}
+
+func TestWatchLatestVersion(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://example.com").
+		Get("/downloads/").
+		Reply(200).
+		BodyString(watchPage)
+
+	watch, err := parseWatchLine(`https://example.com/downloads/ foo-([\d.]+)\.tar\.gz`, "foo")
+	require.NoError(t, err)
+	watchProv := newWatchProvider(watch, testHTTPClient)
+
+	result, err := watchProv.LatestVersion()
+
+	assert.NoError(t, err)
+	assert.Equal(t, Version("1.10.0"), result)
+	assert.Equal(t, "1.10.0", watchProv.LatestRawVersion())
+}
+
+func TestWatchLatestVersion_RawVersion(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://example.com").
+		Get("/downloads/").
+		Reply(200).
+		BodyString(watchPage)
+
+	watch, err := parseWatchLine(`opts=uversionmangle=s/-rc/rc/ https://example.com/downloads/ foo-([\d.]+(?:-rc\d+)?)\.tar\.gz`, "foo")
+	require.NoError(t, err)
+	watchProv := newWatchProvider(watch, testHTTPClient)
+
+	result, err := watchProv.LatestVersion()
+
+	assert.NoError(t, err)
+	assert.Equal(t, Version("1.11.0rc1"), result)
+	assert.Equal(t, "1.11.0-rc1", watchProv.LatestRawVersion())
+}
+
+func TestWatchLatestVersion_NotFound(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://example.com").
+		Get("/downloads/").
+		Reply(200).
+		BodyString(watchPage)
+
+	watch, err := parseWatchLine(`https://example.com/downloads/ bar-([\d.]+)\.tar\.gz`, "foo")
+	require.NoError(t, err)
+	watchProv := newWatchProvider(watch, testHTTPClient)
+
+	_, err = watchProv.LatestVersion()
+
+	assert.ErrorIs(t, err, ErrVersionNotFound)
+}
+
+func TestNewWatchProvider_NotConfigured(t *testing.T) {
+	assert.Nil(t, newWatchProvider(nil, testHTTPClient))
+}
+
+func TestWatchEqual(t *testing.T) {
+	watchA, _ := parseWatchLine(`https://example.com/ foo-(.+)\.tgz`, "foo")
+	watchB, _ := parseWatchLine(`https://example.com/ bar-(.+)\.tgz`, "foo")
+	watchProv := &watchProvider{watch: watchA}
+
+	assert.True(t, watchProv.Equal(&watchProvider{watch: watchA}))
+	assert.False(t, watchProv.Equal(&watchProvider{watch: watchB}))
+	assert.False(t, watchProv.Equal(&pypiProvider{packageName: "foo"}))
+}